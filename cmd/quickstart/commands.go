@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"example.com/weaviate-tutorial/internal/cli"
+	"example.com/weaviate-tutorial/internal/preflight"
+	"example.com/weaviate-tutorial/pkg/academy"
+	"example.com/weaviate-tutorial/schemas"
+	"flag"
+	"github.com/weaviate/weaviate-go-client/v4/weaviate"
+)
+
+func commands(client *weaviate.Client, pf *preflight.Checker, aliases *academy.Aliases) []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:    "schema",
+			Summary: "manage the Question class",
+			Subcommands: []*cli.Command{
+				{
+					Name:    "create",
+					Usage:   "[--class] [--ensure|--recreate]",
+					Summary: "create the class with the contextionary vectorizer",
+					Run: func(ctx context.Context, args []string) error {
+						fs := cli.NewFlagSet("schema create")
+						className := fs.String("class", "Question", "class name")
+						ensure := fs.Bool("ensure", false, "leave an existing class alone if it matches, report drift otherwise")
+						recreate := fs.Bool("recreate", false, "delete and recreate an existing class that has drifted, losing its objects")
+						if err := cli.Parse(fs, args); err != nil {
+							return err
+						}
+						class, err := schemas.Class("Question")
+						if err != nil {
+							return err
+						}
+						class.Class = *className
+						if err := pf.RequireClasses(ctx, class); err != nil {
+							return err
+						}
+						return QuestionSchemaCreate(ctx, client, class, *ensure, *recreate)
+					},
+				},
+			},
+		},
+		{
+			Name:    "import",
+			Usage:   "[--class] [--url] [--batch-size] [--workers] [--queue] [--retries] [--backoff] [--dead-letter]",
+			Summary: "batch import the jeopardy_tiny dataset",
+			Run: func(ctx context.Context, args []string) error {
+				fs := cli.NewFlagSet("import")
+				className := fs.String("class", "Question", "class name or alias")
+				url := fs.String("url", jeopardyTinyURL, "URL of the JSON dataset")
+				opts := cli.ImportFlags(fs)
+				deadLetter := fs.String("dead-letter", "deadletter.jsonl", "file for the objects that finally fail, empty to discard them")
+				if err := cli.Parse(fs, args); err != nil {
+					return err
+				}
+				if err := pf.Require(ctx, preflight.Requirements{Modules: []string{"text2vec-*"}}); err != nil {
+					return err
+				}
+				class, err := aliases.Resolve(ctx, *className)
+				if err != nil {
+					return err
+				}
+				return QuestionsImport(ctx, client, class, *url, *deadLetter, *opts)
+			},
+		},
+		{
+			Name:    "near-text",
+			Usage:   "[--concept] [--limit]",
+			Summary: "semantic search with nearText",
+			Run: func(ctx context.Context, args []string) error {
+				fs := cli.NewFlagSet("near-text")
+				opts := queryFlags(fs, aliases)
+				if err := cli.Parse(fs, args); err != nil {
+					return err
+				}
+				if err := pf.Require(ctx, preflight.Requirements{Modules: []string{"text2vec-*"}}); err != nil {
+					return err
+				}
+				o, err := opts(ctx)
+				if err != nil {
+					return err
+				}
+				return QuestionsNearText(ctx, client, o)
+			},
+		},
+		{
+			Name:    "where",
+			Usage:   "--path --eq",
+			Summary: "nearText search filtered by a text property",
+			Run: func(ctx context.Context, args []string) error {
+				fs := cli.NewFlagSet("where")
+				opts := queryFlags(fs, aliases)
+				path := fs.String("path", "category", "property path to filter on")
+				eq := fs.String("eq", "ANIMALS", "value the property must equal")
+				if err := cli.Parse(fs, args); err != nil {
+					return err
+				}
+				if err := pf.Require(ctx, preflight.Requirements{Modules: []string{"text2vec-*"}}); err != nil {
+					return err
+				}
+				o, err := opts(ctx)
+				if err != nil {
+					return err
+				}
+				return QuestionsWhere(ctx, client, o, *path, *eq)
+			},
+		},
+		{
+			Name:    "generate",
+			Summary: "generative search over nearText results",
+			Subcommands: []*cli.Command{
+				{
+					Name:    "single",
+					Usage:   "--prompt",
+					Summary: "one generation per result, {property} placeholders allowed",
+					Run: func(ctx context.Context, args []string) error {
+						fs := cli.NewFlagSet("generate single")
+						opts := queryFlags(fs, aliases)
+						prompt := fs.String("prompt", "Explain {answer} as you might to a five-year-old.", "single result prompt")
+						if err := cli.Parse(fs, args); err != nil {
+							return err
+						}
+						if err := pf.Require(ctx, generativeRequirements); err != nil {
+							return err
+						}
+						o, err := opts(ctx)
+						if err != nil {
+							return err
+						}
+						return QuestionsGenerativeSingle(ctx, client, o, *prompt)
+					},
+				},
+				{
+					Name:    "grouped",
+					Usage:   "--prompt",
+					Summary: "one generation over all results",
+					Run: func(ctx context.Context, args []string) error {
+						fs := cli.NewFlagSet("generate grouped")
+						opts := queryFlags(fs, aliases)
+						prompt := fs.String("prompt", "Write a tweet with emojis about these facts.", "grouped result task")
+						if err := cli.Parse(fs, args); err != nil {
+							return err
+						}
+						if err := pf.Require(ctx, generativeRequirements); err != nil {
+							return err
+						}
+						o, err := opts(ctx)
+						if err != nil {
+							return err
+						}
+						return QuestionsGenerativeGrouped(ctx, client, o, *prompt)
+					},
+				},
+			},
+		},
+	}
+}
+
+var generativeRequirements = preflight.Requirements{
+	Modules:  []string{"text2vec-*", "generative-openai"},
+	Features: []preflight.Feature{preflight.FeatureGenerative},
+}
+
+// queryFlags registers the flags shared by the query commands and returns a
+// function building QueryOptions once fs has been parsed, with the class
+// name resolved through aliases.
+func queryFlags(fs *flag.FlagSet, aliases *academy.Aliases) func(ctx context.Context) (QueryOptions, error) {
+	className := fs.String("class", "Question", "class name or alias")
+	fields := cli.NewStringList("question", "answer", "category")
+	fs.Var(fields, "fields", "comma separated properties to return")
+	concepts := cli.NewStringList("biology")
+	fs.Var(concepts, "concept", "nearText concept, repeatable")
+	limit := fs.Int("limit", 2, "maximum number of results")
+	return func(ctx context.Context) (QueryOptions, error) {
+		class, err := aliases.Resolve(ctx, *className)
+		return QueryOptions{
+			ClassName: class,
+			Fields:    fields.Values(),
+			Concepts:  concepts.Values(),
+			Limit:     *limit,
+		}, err
+	}
+}
@@ -4,215 +4,144 @@ package main
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
+	"example.com/weaviate-tutorial/internal/cli"
+	"example.com/weaviate-tutorial/internal/config"
+	"example.com/weaviate-tutorial/internal/preflight"
+	"example.com/weaviate-tutorial/pkg/academy"
 	"github.com/weaviate/weaviate-go-client/v4/weaviate"
 	"github.com/weaviate/weaviate-go-client/v4/weaviate/filters"
-	"github.com/weaviate/weaviate-go-client/v4/weaviate/graphql"
 	"github.com/weaviate/weaviate/entities/models"
 	"net/http"
 	"os"
 )
 
-func main() {
-	headers := make(map[string]string)
-	headers["X-OpenAI-Api-Key"] = os.Getenv("OPENAI_APIKEY")
-
-	cfg := weaviate.Config{
-		Host:   os.Getenv("WEAVIATE_HOST"),
-		Scheme: "http",
-		//Scheme: "https",
-		//AuthConfig: auth.ApiKey{Value: os.Getenv("WEAVIATE_API_KEY")},
-		Headers: headers,
-	}
-	client, err := weaviate.NewClient(cfg)
-	if err != nil {
-		panic(err)
-	}
+const jeopardyTinyURL = "https://raw.githubusercontent.com/weaviate-tutorials/quickstart/main/data/jeopardy_tiny.json"
 
-	QuestionsGenerativeGrouped(client)
-	//QuestionsGenerativeSingle(client)
-	//QuestionsWhere(client)
-	//QuestionsNearText(client)
-	//QuestionsImport(client)
-	//QuestionSchemaCreate(client)
+// QueryOptions selects the class, returned fields and nearText concepts shared
+// by every quickstart query.
+type QueryOptions struct {
+	ClassName string
+	Fields    []string
+	Concepts  []string
+	Limit     int
 }
 
-func QuestionsGenerativeGrouped(client *weaviate.Client) {
-	fields := []graphql.Field{
-		{Name: "question"},
-		{Name: "answer"},
-		{Name: "category"},
+func (o QueryOptions) search() academy.Search {
+	return academy.Search{
+		ClassName:  o.ClassName,
+		Properties: o.Fields,
+		Limit:      o.Limit,
+		NearText:   &academy.NearText{Concepts: o.Concepts},
 	}
+}
 
-	nearText := client.GraphQL().
-		NearTextArgBuilder().
-		WithConcepts([]string{"biology"})
-
-	generativeSearch := graphql.NewGenerativeSearch().
-		GroupedResult("Write a tweet with emojis about these facts.")
-
-	result, err := client.GraphQL().Get().
-		WithClassName("Question").
-		WithFields(fields...).
-		WithNearText(nearText).
-		WithLimit(2).
-		WithGenerativeSearch(generativeSearch).
-		Do(context.Background())
+func main() {
+	fs := cli.NewFlagSet("quickstart")
+	conn := config.RegisterFlags(fs)
+	pfOptions := preflight.RegisterFlags(fs)
+	args, err := cli.ParseGlobal(fs, commands(nil, nil, nil), os.Args[1:])
 	if err != nil {
-		panic(err)
+		cli.Exit("quickstart", err)
 	}
 
-	jsonOutput, err := json.MarshalIndent(result, "", "  ")
+	settings, err := conn.Resolve("env")
 	if err != nil {
-		panic(err)
+		cli.Exit("quickstart", err)
 	}
-	fmt.Println(string(jsonOutput))
-}
-
-func QuestionsGenerativeSingle(client *weaviate.Client) {
-	fields := []graphql.Field{
-		{Name: "question"},
-		{Name: "answer"},
-		{Name: "category"},
+	client, err := settings.NewClient()
+	if err != nil {
+		cli.Exit("quickstart", err)
 	}
 
-	nearText := client.GraphQL().
-		NearTextArgBuilder().
-		WithConcepts([]string{"biology"})
+	pfOptions.Headers = settings.Headers
+	pf := preflight.New(client, settings.URL(), *pfOptions)
+	aliases := academy.NewAliases(settings.AliasStore(client))
 
-	generativeSearch := graphql.NewGenerativeSearch().
-		SingleResult("Explain {answer} as you might to a five-year-old.")
+	cli.Exit("quickstart", cli.Run(context.Background(), "quickstart", commands(client, pf, aliases), args))
+}
 
-	result, err := client.GraphQL().Get().
-		WithClassName("Question").
-		WithFields(fields...).
-		WithNearText(nearText).
-		WithLimit(2).
-		WithGenerativeSearch(generativeSearch).
-		Do(context.Background())
-	if err != nil {
-		panic(err)
-	}
+func QuestionsGenerativeGrouped(ctx context.Context, client *weaviate.Client, opts QueryOptions, prompt string) error {
+	search := opts.search()
+	search.Generative = &academy.Generative{GroupedResult: prompt}
+	return printSearch(ctx, client, search)
+}
 
-	jsonOutput, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		panic(err)
-	}
-	fmt.Println(string(jsonOutput))
+func QuestionsGenerativeSingle(ctx context.Context, client *weaviate.Client, opts QueryOptions, prompt string) error {
+	search := opts.search()
+	search.Generative = &academy.Generative{SingleResult: prompt}
+	return printSearch(ctx, client, search)
 }
 
-func QuestionsWhere(client *weaviate.Client) {
-	fields := []graphql.Field{
-		{Name: "question"},
-		{Name: "answer"},
-		{Name: "category"},
+func QuestionsWhere(ctx context.Context, client *weaviate.Client, opts QueryOptions, path, eq string) error {
+	search := opts.search()
+	if len(opts.Concepts) == 0 {
+		search.NearText = nil
 	}
-
-	nearText := client.GraphQL().
-		NearTextArgBuilder().
-		WithConcepts([]string{"biology"})
-
-	where := filters.Where().
-		WithPath([]string{"category"}).
+	search.Where = filters.Where().
+		WithPath([]string{path}).
 		WithOperator(filters.Equal).
-		WithValueText("ANIMALS")
-
-	result, err := client.GraphQL().Get().
-		WithClassName("Question").
-		WithFields(fields...).
-		WithNearText(nearText).
-		WithWhere(where).
-		WithLimit(2).
-		Do(context.Background())
-	if err != nil {
-		panic(err)
-	}
-
-	fmt.Printf("%v", result)
+		WithValueText(eq)
+	return printSearch(ctx, client, search)
 }
 
-func QuestionsNearText(client *weaviate.Client) {
-	fields := []graphql.Field{
-		{Name: "question"},
-		{Name: "answer"},
-		{Name: "category"},
-	}
-
-	nearText := client.GraphQL().
-		NearTextArgBuilder().
-		WithConcepts([]string{"biology"})
+func QuestionsNearText(ctx context.Context, client *weaviate.Client, opts QueryOptions) error {
+	return printSearch(ctx, client, opts.search())
+}
 
-	result, err := client.GraphQL().Get().
-		WithClassName("Question").
-		WithFields(fields...).
-		WithNearText(nearText).
-		WithLimit(2).
-		Do(context.Background())
-	if err != nil {
-		panic(err)
+func printSearch(ctx context.Context, client *weaviate.Client, search academy.Search) error {
+	result, err := search.Run(ctx, client)
+	if err := cli.Tolerate(err); err != nil {
+		return err
 	}
-
-	fmt.Printf("%v", result)
+	return cli.Print(result)
 }
 
-func QuestionsImport(client *weaviate.Client) {
+// QuestionsImport writes the dataset at url to className, retrying transient
+// failures and writing the objects that finally fail to deadLetter. On Ctrl-C
+// it stops reading and waits for the batches already read.
+func QuestionsImport(ctx context.Context, client *weaviate.Client, className, url, deadLetter string, opts academy.ImportOptions) error {
 	// Retrieve the data
-	data, err := http.DefaultClient.Get("https://raw.githubusercontent.com/weaviate-tutorials/quickstart/main/data/jeopardy_tiny.json")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		panic(err)
-	}
-	defer data.Body.Close()
-
-	// Decode the data
-	var items []map[string]string
-	if err := json.NewDecoder(data.Body).Decode(&items); err != nil {
-		panic(err)
+		return err
 	}
-
-	// convert items into a slice of models.Object
-	objects := make([]*models.Object, len(items))
-	for i := range items {
-		objects[i] = &models.Object{
-			Class: "Question",
-			Properties: map[string]any{
-				"category": items[i]["Category"],
-				"question": items[i]["Question"],
-				"answer":   items[i]["Answer"],
-			},
-		}
-	}
-
-	// batch write items
-	batchRes, err := client.Batch().ObjectsBatcher().WithObjects(objects...).Do(context.Background())
+	data, err := http.DefaultClient.Do(req)
 	if err != nil {
-		panic(err)
-	}
-	for _, res := range batchRes {
-		if res.Result.Errors != nil {
-			panic(res.Result.Errors.Error)
-		}
+		return err
 	}
+	defer data.Body.Close()
+	if data.StatusCode != http.StatusOK {
+		return academy.Errorf(academy.KindUnknown, "download "+url, "status %s", data.Status)
+	}
+
+	// Decode the items one by one and write them in batches
+	return cli.RunImport(ctx, client, &cli.DeadLetterFile{Path: deadLetter}, opts, func(ctx context.Context, im *academy.Importer) error {
+		return academy.StreamArray(data.Body, func(item map[string]string) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			return im.Add(&models.Object{
+				Class: className,
+				Properties: map[string]any{
+					"category": item["Category"],
+					"question": item["Question"],
+					"answer":   item["Answer"],
+				},
+			})
+		})
+	})
 }
 
-func QuestionSchemaCreate(client *weaviate.Client) {
-	className := "Question"
-	class := &models.Class{
-		Class:      className,
-		Vectorizer: "text2vec-contextionary",
-		ModuleConfig: map[string]any{
-			"text2vec-contextionary": map[string]any{
-				"skip":                  false,
-				"vectorizePropertyName": false,
-			},
-			"generative-openai": map[string]interface{}{},
-		},
+func QuestionSchemaCreate(ctx context.Context, client *weaviate.Client, class *models.Class, ensure, recreate bool) error {
+	if !ensure && !recreate {
+		return academy.CreateClass(ctx, client, class)
 	}
 
-	err := client.Schema().ClassCreator().
-		WithClass(class).
-		Do(context.Background())
-	if err != nil {
-		panic(err)
+	res, err := academy.EnsureClass(ctx, client, class, recreate)
+	if res != nil {
+		if err := cli.Print(res); err != nil {
+			return err
+		}
 	}
+	return err
 }
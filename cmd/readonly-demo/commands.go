@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"example.com/weaviate-tutorial/internal/cli"
+	"example.com/weaviate-tutorial/internal/preflight"
+	"example.com/weaviate-tutorial/pkg/academy"
+	"fmt"
+	"github.com/weaviate/weaviate-go-client/v4/weaviate"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// recipeArgs splits "<name> [flags]" and looks up the recipe.
+func recipeArgs(cmd string, args []string) (*Recipe, []string, error) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		fmt.Fprintf(os.Stderr, "Usage: readonly-demo %s <recipe> [flags]\n", cmd)
+		return nil, nil, cli.ErrUsage
+	}
+	r, err := findRecipe(cmd, args[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	return r, args[1:], nil
+}
+
+func commands(client *weaviate.Client, pf *preflight.Checker, aliases *academy.Aliases) []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:    "list",
+			Summary: "list the available recipes",
+			Run: func(ctx context.Context, args []string) error {
+				if err := cli.Parse(cli.NewFlagSet("list"), args); err != nil {
+					return err
+				}
+				w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+				for _, r := range recipes {
+					fmt.Fprintf(w, "%s\t%s\n", r.Name, r.Summary)
+				}
+				return w.Flush()
+			},
+		},
+		{
+			Name:    "describe",
+			Usage:   "<recipe>",
+			Summary: "show a recipe and its parameters",
+			Run: func(ctx context.Context, args []string) error {
+				r, rest, err := recipeArgs("describe", args)
+				if err != nil {
+					return err
+				}
+				if err := cli.Parse(cli.NewFlagSet("describe"), rest); err != nil {
+					return err
+				}
+				fmt.Printf("%s: %s\n", r.Name, r.Summary)
+				if len(r.Params) == 0 {
+					fmt.Println("\nNo parameters.")
+					return nil
+				}
+				fs := cli.NewFlagSet("run " + r.Name)
+				r.bind(fs)
+				fs.SetOutput(os.Stdout)
+				fmt.Println("\nParameters:")
+				fs.PrintDefaults()
+				return nil
+			},
+		},
+		{
+			Name:    "run",
+			Usage:   "<recipe> [flags]",
+			Summary: "run a recipe, overriding parameters with flags",
+			Run: func(ctx context.Context, args []string) error {
+				r, rest, err := recipeArgs("run", args)
+				if err != nil {
+					return err
+				}
+				fs := cli.NewFlagSet("run " + r.Name)
+				params := r.bind(fs)
+				if err := cli.Parse(fs, rest); err != nil {
+					return err
+				}
+				if err := pf.Require(ctx, r.Requires); err != nil {
+					return err
+				}
+				p := params()
+				if p.Class != "" {
+					if p.Class, err = aliases.Resolve(ctx, p.Class); err != nil {
+						return err
+					}
+				}
+				return r.Run(ctx, client, p)
+			},
+		},
+	}
+}
@@ -4,48 +4,49 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"example.com/weaviate-tutorial/internal/cli"
+	"example.com/weaviate-tutorial/internal/config"
+	"example.com/weaviate-tutorial/internal/preflight"
+	"example.com/weaviate-tutorial/pkg/academy"
+	"example.com/weaviate-tutorial/pkg/academy/classes"
 	"fmt"
 	"github.com/weaviate/weaviate-go-client/v4/weaviate"
-	"github.com/weaviate/weaviate-go-client/v4/weaviate/auth"
 	"github.com/weaviate/weaviate-go-client/v4/weaviate/filters"
 	"github.com/weaviate/weaviate-go-client/v4/weaviate/graphql"
-	"log"
 	"os"
 )
 
 func main() {
-	headers := make(map[string]string)
-	headers["X-OpenAI-Api-Key"] = os.Getenv("OPENAI_APIKEY")
-	headers["X-OpenAI-Organization"] = os.Getenv("OPENAI_ORG")
+	fs := cli.NewFlagSet("readonly-demo")
+	conn := config.RegisterFlags(fs)
+	pfOptions := preflight.RegisterFlags(fs)
+	args, err := cli.ParseGlobal(fs, commands(nil, nil, nil), os.Args[1:])
+	if err != nil {
+		cli.Exit("readonly-demo", err)
+	}
 
-	cfg := weaviate.Config{
-		Host:       "edu-demo.weaviate.network",
-		Scheme:     "https",
-		AuthConfig: auth.ApiKey{Value: "readonly-demo"},
-		Headers:    headers,
+	settings, err := conn.Resolve("edu-demo")
+	if err != nil {
+		cli.Exit("readonly-demo", err)
 	}
-	client, err := weaviate.NewClient(cfg)
+	client, err := settings.NewClient()
 	if err != nil {
-		panic(err)
+		cli.Exit("readonly-demo", err)
+	}
+
+	pfOptions.Headers = settings.Headers
+	pf := preflight.New(client, settings.URL(), *pfOptions)
+	// The demo server is read-only, so the aliases always live in a file.
+	store, ok := settings.AliasStore(client).(academy.FileAliasStore)
+	if !ok {
+		store = academy.FileAliasStore{Path: config.DefaultAliasesFile}
 	}
+	aliases := academy.NewAliases(store)
 
-	//DemoJeopardyQuestionAggregateWithNearTextWhereMultiple(client)
-	//DemoJeopardyQuestionAggregateWithNearTextWhere(client)
-	//DemoJeopardyQuestionAggregateWithNearTextGrouped(client)
-	//DemoJeopardyQuestionAggregate(client)
-	//DemoJeopardyQuestionNearText(client)
-	//DemoJeopardyQuestionNearObject(client)
-	//DemoTweetRaw(client)
-	//DemoTweet(client)
-	//DemoLondonOlympicsRaw(client)
-	//DemoLondonOlympics(client)
-	//DemoMajorCities(client)
-	//GetMeta(client)
-	GetSchema(client)
+	cli.Exit("readonly-demo", cli.Run(context.Background(), "readonly-demo", commands(client, pf, aliases), args))
 }
 
-func DemoJeopardyQuestionAggregateWithNearTextWhereMultiple(client *weaviate.Client) {
+func DemoJeopardyQuestionAggregateWithNearTextWhereMultiple(ctx context.Context, client *weaviate.Client, p DemoParams) error {
 	/*
 		{
 		  Get {
@@ -83,49 +84,30 @@ func DemoJeopardyQuestionAggregateWithNearTextWhereMultiple(client *weaviate.Cli
 		}
 	*/
 
-	res, err := client.GraphQL().Get().
-		WithClassName("JeopardyQuestion").
-		WithFields([]graphql.Field{
-			{Name: "question"},
-			{Name: "answer"},
-			{Name: "points"},
-			{Name: "_additional", Fields: []graphql.Field{
-				{Name: "distance"},
-				{Name: "id"},
-			}},
-		}...).
-		WithNearText(client.GraphQL().
-			NearTextArgBuilder().
-			WithConcepts([]string{"Intergalactic travel"}),
-		).
-		WithWhere(filters.Where().
+	return printRows[classes.JeopardyQuestion](ctx, client, academy.Search{
+		ClassName:  p.Class,
+		Properties: classes.Names(classes.JeopardyQuestionQuestion, classes.JeopardyQuestionAnswer, classes.JeopardyQuestionPoints),
+		Additional: academy.Names("distance", "id"),
+		Limit:      p.Limit,
+		NearText:   p.nearText(),
+		Where: filters.Where().
 			WithOperator(filters.And).
 			WithOperands(
 				[]*filters.WhereBuilder{
 					filters.Where().
-						WithPath([]string{"question"}).
+						WithPath(classes.Names(classes.JeopardyQuestionQuestion)).
 						WithOperator(filters.Like).
-						WithValueText("*rocket*"),
+						WithValueText(p.Like),
 					filters.Where().
-						WithPath([]string{"points"}).
+						WithPath(classes.Names(classes.JeopardyQuestionPoints)).
 						WithOperator(filters.GreaterThan).
-						WithValueInt(400),
+						WithValueInt(int64(p.MinPoints)),
 				},
 			),
-		).
-		WithLimit(2).
-		Do(context.Background())
-	if err != nil {
-		panic(err)
-	}
-	b, err := json.MarshalIndent(res, "", "  ")
-	if err != nil {
-		log.Fatal(err)
-	}
-	fmt.Println(string(b))
+	})
 }
 
-func DemoJeopardyQuestionAggregateWithNearTextWhere(client *weaviate.Client) {
+func DemoJeopardyQuestionAggregateWithNearTextWhere(ctx context.Context, client *weaviate.Client, p DemoParams) error {
 	/*
 		{
 		  Get {
@@ -151,38 +133,20 @@ func DemoJeopardyQuestionAggregateWithNearTextWhere(client *weaviate.Client) {
 		}
 	*/
 
-	res, err := client.GraphQL().Get().
-		WithClassName("JeopardyQuestion").
-		WithFields([]graphql.Field{
-			{Name: "question"},
-			{Name: "answer"},
-			{Name: "_additional", Fields: []graphql.Field{
-				{Name: "distance"},
-				{Name: "id"},
-			}},
-		}...).
-		WithNearText(client.GraphQL().
-			NearTextArgBuilder().
-			WithConcepts([]string{"Intergalactic travel"}),
-		).
-		WithWhere(filters.Where().
-			WithPath([]string{"question"}).
+	return printRows[classes.JeopardyQuestion](ctx, client, academy.Search{
+		ClassName:  p.Class,
+		Properties: classes.Names(classes.JeopardyQuestionQuestion, classes.JeopardyQuestionAnswer),
+		Additional: academy.Names("distance", "id"),
+		Limit:      p.Limit,
+		NearText:   p.nearText(),
+		Where: filters.Where().
+			WithPath(classes.Names(classes.JeopardyQuestionQuestion)).
 			WithOperator(filters.Like).
-			WithValueText("*rocket*"),
-		).
-		WithLimit(2).
-		Do(context.Background())
-	if err != nil {
-		panic(err)
-	}
-	b, err := json.MarshalIndent(res, "", "  ")
-	if err != nil {
-		log.Fatal(err)
-	}
-	fmt.Println(string(b))
+			WithValueText(p.Like),
+	})
 }
 
-func DemoJeopardyQuestionAggregateWithNearTextGrouped(client *weaviate.Client) {
+func DemoJeopardyQuestionAggregateWithNearTextGrouped(ctx context.Context, client *weaviate.Client, p DemoParams) error {
 	/*
 		{
 		  Aggregate {
@@ -204,35 +168,22 @@ func DemoJeopardyQuestionAggregateWithNearTextGrouped(client *weaviate.Client) {
 		  }
 		}
 	*/
-	res, err := client.GraphQL().Aggregate().
-		WithClassName("JeopardyQuestion").
-		WithFields([]graphql.Field{
-			{Name: "groupedBy", Fields: []graphql.Field{
-				{Name: "path"},
-				{Name: "value"},
-			}},
-			{Name: "meta", Fields: []graphql.Field{
-				{Name: "count"},
-			}},
-		}...).
-		WithNearText(client.GraphQL().
-			NearTextArgBuilder().
-			WithConcepts([]string{"Intergalactic travel"}).
-			WithDistance(0.2),
-		).
-		WithGroupBy("round").
-		Do(context.Background())
-	if err != nil {
-		panic(err)
-	}
-	b, err := json.MarshalIndent(res, "", "  ")
-	if err != nil {
-		log.Fatal(err)
-	}
-	fmt.Println(string(b))
+	res, err := academy.Aggregation{
+		ClassName: p.Class,
+		Fields: []graphql.Field{
+			{Name: "groupedBy", Fields: academy.Names("path", "value")},
+			{Name: "meta", Fields: academy.Names("count")},
+		},
+		GroupBy:  p.GroupBy,
+		NearText: p.nearText(),
+	}.Run(ctx, client)
+	if err := cli.Tolerate(err); err != nil {
+		return err
+	}
+	return cli.Print(res)
 }
 
-func DemoJeopardyQuestionAggregate(client *weaviate.Client) {
+func DemoJeopardyQuestionAggregate(ctx context.Context, client *weaviate.Client, p DemoParams) error {
 	/*
 		{
 		  Aggregate {
@@ -249,96 +200,54 @@ func DemoJeopardyQuestionAggregate(client *weaviate.Client) {
 		  }
 		}
 	*/
-	res, err := client.GraphQL().Aggregate().
-		WithClassName("JeopardyQuestion").
-		WithFields([]graphql.Field{
-			{Name: "answer", Fields: []graphql.Field{
+	res, err := academy.Aggregation{
+		ClassName: p.Class,
+		Fields: []graphql.Field{
+			{Name: string(classes.JeopardyQuestionAnswer), Fields: []graphql.Field{
 				{Name: "count"},
-				{Name: "topOccurrences", Fields: []graphql.Field{
-					{Name: "value"},
-					{Name: "occurs"},
-				}},
+				{Name: "topOccurrences", Fields: academy.Names("value", "occurs")},
 			}},
-		}...).
-		WithLimit(2).
-		Do(context.Background())
-	if err != nil {
-		panic(err)
-	}
-	b, err := json.MarshalIndent(res, "", "  ")
-	if err != nil {
-		log.Fatal(err)
+		},
+		Limit: p.Limit,
+	}.Run(ctx, client)
+	if err := cli.Tolerate(err); err != nil {
+		return err
 	}
-	fmt.Println(string(b))
+	return cli.Print(res)
 }
 
-func DemoJeopardyQuestionNearObject(client *weaviate.Client) {
-	fields := []graphql.Field{
-		{Name: "question"},
-		{Name: "answer"},
-		{Name: "_additional", Fields: []graphql.Field{
-			{Name: "distance"},
-			{Name: "id"},
-		}},
-	}
-	nearObjectArgumentBuilder := client.GraphQL().NearObjectArgBuilder().
-		WithID("c8f8176c-6f9b-5461-8ab3-f3c7ce8c2f5c")
-
-	res, err := client.GraphQL().Get().
-		WithClassName("JeopardyQuestion").
-		WithFields(fields...).
-		WithNearObject(nearObjectArgumentBuilder).
-		WithLimit(2).
-		Do(context.Background())
-	if err != nil {
-		panic(err)
-	}
-	b, err := json.MarshalIndent(res, "", "  ")
-	if err != nil {
-		log.Fatal(err)
-	}
-	fmt.Println(string(b))
+func DemoJeopardyQuestionNearObject(ctx context.Context, client *weaviate.Client, p DemoParams) error {
+	return printRows[classes.JeopardyQuestion](ctx, client, academy.Search{
+		ClassName:  p.Class,
+		Properties: classes.Names(classes.JeopardyQuestionQuestion, classes.JeopardyQuestionAnswer),
+		Additional: academy.Names("distance", "id"),
+		Limit:      p.Limit,
+		NearObject: &academy.NearObject{ID: p.ID, Distance: float32(p.Distance)},
+	})
 }
 
-func DemoJeopardyQuestionNearText(client *weaviate.Client) {
-	fields := []graphql.Field{
-		{Name: "question"},
-		{Name: "answer"},
-	}
-	nearTextArgumentBuilder := client.GraphQL().NearTextArgBuilder().
-		WithConcepts([]string{"Intergalactic travel"})
-
-	res, err := client.GraphQL().Get().
-		WithClassName("JeopardyQuestion").
-		WithFields(fields...).
-		WithNearText(nearTextArgumentBuilder).
-		WithLimit(2).
-		Do(context.Background())
-	if err != nil {
-		panic(err)
-	}
-	b, err := json.MarshalIndent(res, "", "  ")
-	if err != nil {
-		log.Fatal(err)
-	}
-	fmt.Println(string(b))
+func DemoJeopardyQuestionNearText(ctx context.Context, client *weaviate.Client, p DemoParams) error {
+	return printRows[classes.JeopardyQuestion](ctx, client, academy.Search{
+		ClassName:  p.Class,
+		Properties: classes.Names(classes.JeopardyQuestionQuestion, classes.JeopardyQuestionAnswer),
+		Limit:      p.Limit,
+		NearText:   p.nearText(),
+	})
 }
 
-func DemoTweetRaw(client *weaviate.Client) {
-	res, err := client.GraphQL().Raw().WithQuery(`
+func DemoTweetRaw(ctx context.Context, client *weaviate.Client, p DemoParams) error {
+	res, err := academy.Raw(ctx, client, fmt.Sprintf(`
 {
 	Get {
-		WikiCity(
-			limit: 3
-			nearText: { concepts: "Popular Southeast Asian tourist destination" }
+		%s(
+			limit: %d
+			nearText: { concepts: %s }
 		) {
 			city_name
 			_additional {
 				generate(
 					singleResult: {
-						prompt: """
-						Write a tweet with a potentially surprising fact from {wiki_summary}
-						"""
+						prompt: %s
 					}
 				) {
 					singleResult
@@ -348,53 +257,31 @@ func DemoTweetRaw(client *weaviate.Client) {
 		}
 	}
 }
-`).Do(context.Background())
-	if err != nil {
-		panic(err)
+`, p.Class, p.Limit, gqlString(p.Concepts...), gqlString(p.Prompt)))
+	if err := cli.Tolerate(err); err != nil {
+		return err
 	}
 
-	b, err := json.MarshalIndent(res, "", "  ")
-	if err != nil {
-		log.Fatal(err)
-	}
-	fmt.Println(string(b))
+	return cli.Print(res)
 }
 
-func DemoTweet(client *weaviate.Client) {
-	fields := []graphql.Field{
-		{Name: "city_name"},
-		{Name: "wiki_summary"},
-	}
-	nearTextArgumentBuilder := client.GraphQL().NearTextArgBuilder().
-		WithConcepts([]string{"Popular Southeast Asian tourist destination"})
-
-	searchBuilder := graphql.NewGenerativeSearch().
-		SingleResult("Write a tweet with a potentially surprising fact from {wiki_summary}")
-
-	res, err := client.GraphQL().Get().
-		WithClassName("WikiCity").
-		WithFields(fields...).
-		WithNearText(nearTextArgumentBuilder).
-		WithLimit(3).
-		WithGenerativeSearch(searchBuilder).
-		Do(context.Background())
-	if err != nil {
-		panic(err)
-	}
-	b, err := json.MarshalIndent(res, "", "  ")
-	if err != nil {
-		log.Fatal(err)
-	}
-	fmt.Println(string(b))
+func DemoTweet(ctx context.Context, client *weaviate.Client, p DemoParams) error {
+	return printRows[classes.WikiCity](ctx, client, academy.Search{
+		ClassName:  p.Class,
+		Properties: classes.Names(classes.WikiCityCityName, classes.WikiCityWikiSummary),
+		Limit:      p.Limit,
+		NearText:   p.nearText(),
+		Generative: &academy.Generative{SingleResult: p.Prompt},
+	})
 }
 
-func DemoLondonOlympicsRaw(client *weaviate.Client) {
-	res, err := client.GraphQL().Raw().WithQuery(`{
+func DemoLondonOlympicsRaw(ctx context.Context, client *weaviate.Client, p DemoParams) error {
+	res, err := academy.Raw(ctx, client, fmt.Sprintf(`{
 	Get {
-		WikiCity(
-			limit: 1
+		%s(
+			limit: %d
 			ask: {
-				question: "When was the London Olympics?"
+				question: %s
 				properties: ["wiki_summary"]
 			}
 		) {
@@ -412,112 +299,44 @@ func DemoLondonOlympicsRaw(client *weaviate.Client) {
 		}
 	}
 }
-`).Do(context.Background())
-	if err != nil {
-		panic(err)
+`, p.Class, p.Limit, gqlString(p.Question)))
+	if err := cli.Tolerate(err); err != nil {
+		return err
 	}
 
-	b, err := json.MarshalIndent(res, "", "  ")
-	if err != nil {
-		log.Fatal(err)
-	}
-	fmt.Println(string(b))
-}
-
-func DemoLondonOlympics(client *weaviate.Client) {
-	fields := []graphql.Field{
-		{Name: "city_name"},
-		{Name: "country"},
-		{Name: "lng"},
-		{Name: "lat"},
-		{Name: "_additional", Fields: []graphql.Field{
-			{Name: "answer", Fields: []graphql.Field{
-				{Name: "hasAnswer"},
-				{Name: "property"},
-				{Name: "result"},
-			}},
-		}},
-	}
-
-	askArgumentBuilder := client.GraphQL().AskArgBuilder().
-		WithQuestion("When was the London Olympics?").
-		WithProperties([]string{"wiki_summary"})
-
-	res, err := client.GraphQL().Get().
-		WithClassName("WikiCity").
-		WithFields(fields...).
-		WithAsk(askArgumentBuilder).
-		WithLimit(1).
-		Do(context.Background())
-
-	if err != nil {
-		panic(err)
-	}
-	b, err := json.MarshalIndent(res, "", "  ")
-	if err != nil {
-		log.Fatal(err)
-	}
-	fmt.Println(string(b))
+	return cli.Print(res)
 }
 
-func DemoMajorCities(client *weaviate.Client) {
-	fields := []graphql.Field{
-		{Name: "city_name"},
-		{Name: "country"},
-		{Name: "lng"},
-		{Name: "lat"},
-	}
-	nearTextArgumentBuilder := client.GraphQL().NearTextArgBuilder().
-		WithConcepts([]string{"Major European city"})
-
-	res, err := client.GraphQL().Get().
-		WithClassName("WikiCity").
-		WithFields(fields...).
-		WithNearText(nearTextArgumentBuilder).
-		WithLimit(3).
-		Do(context.Background())
-	if err != nil {
-		panic(err)
-	}
-	b, err := json.MarshalIndent(res, "", "  ")
-	if err != nil {
-		log.Fatal(err)
-	}
-	fmt.Println(string(b))
+func DemoLondonOlympics(ctx context.Context, client *weaviate.Client, p DemoParams) error {
+	return printRows[classes.WikiCity](ctx, client, academy.Search{
+		ClassName:  p.Class,
+		Properties: classes.Names(classes.WikiCityCityName, classes.WikiCityCountry, classes.WikiCityLng, classes.WikiCityLat),
+		Additional: []graphql.Field{
+			{Name: "answer", Fields: academy.Names("hasAnswer", "property", "result")},
+		},
+		Limit: p.Limit,
+		Ask: &academy.Ask{
+			Question:   p.Question,
+			Properties: classes.Names(classes.WikiCityWikiSummary),
+		},
+	})
 }
 
-func GetSchema(client *weaviate.Client) {
-	schema, err := client.Schema().Getter().Do(context.Background())
-	if err != nil {
-		panic(err)
-	}
-
-	b, err := json.MarshalIndent(schema, "", "  ")
-	if err != nil {
-		log.Fatal(err)
-	}
-	fmt.Println(string(b))
+func DemoMajorCities(ctx context.Context, client *weaviate.Client, p DemoParams) error {
+	return printRows[classes.WikiCity](ctx, client, academy.Search{
+		ClassName:  p.Class,
+		Properties: classes.Names(classes.WikiCityCityName, classes.WikiCityCountry, classes.WikiCityLng, classes.WikiCityLat),
+		Limit:      p.Limit,
+		NearText:   p.nearText(),
+	})
 }
 
-func GetMeta(client *weaviate.Client) {
-	meta, err := client.Misc().MetaGetter().Do(context.Background())
-	if err != nil {
-		panic(err)
-	}
-	//fmt.Println(meta.Hostname)
-	//fmt.Println(meta.Version)
-
-	//if rec, ok := meta.Modules.(map[string]interface{}); ok {
-	//	for key, val := range rec {
-	//		log.Printf(" [========>] %s = %s", key, val)
-	//	}
-	//} else {
-	//	fmt.Printf("record not a map[string]interface{}: %v\n", meta.Modules)
-	//}
-
-	b, err := json.MarshalIndent(meta, "", "  ")
-	if err != nil {
-		log.Fatal(err)
+// printRows runs search and prints the results decoded into rows of type T,
+// one of the generated class types.
+func printRows[T any](ctx context.Context, client *weaviate.Client, search academy.Search) error {
+	rows, err := academy.SearchRows[T](ctx, client, search)
+	if err := cli.Tolerate(err); err != nil {
+		return err
 	}
-	fmt.Println(string(b))
+	return cli.Print(rows)
 }
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"example.com/weaviate-tutorial/internal/cli"
+	"example.com/weaviate-tutorial/internal/preflight"
+	"example.com/weaviate-tutorial/pkg/academy"
+	"example.com/weaviate-tutorial/pkg/academy/classes"
+	"flag"
+	"github.com/weaviate/weaviate-go-client/v4/weaviate"
+)
+
+// DemoParams holds the tunable inputs of the demo queries. Each recipe only
+// reads the parameters it declares.
+type DemoParams struct {
+	Class     string
+	Concepts  []string
+	Distance  float64
+	Limit     int
+	Prompt    string
+	Question  string
+	ID        string
+	Like      string
+	MinPoints int
+	GroupBy   string
+}
+
+func (p DemoParams) nearText() *academy.NearText {
+	return &academy.NearText{Concepts: p.Concepts, Distance: float32(p.Distance)}
+}
+
+// gqlString renders values as a GraphQL string literal, or a list of them
+// when more than one value is given.
+func gqlString(values ...string) string {
+	var b []byte
+	if len(values) == 1 {
+		b, _ = json.Marshal(values[0])
+	} else {
+		b, _ = json.Marshal(values)
+	}
+	return string(b)
+}
+
+// Recipe is a named demo query together with the parameters that can be
+// overridden from the command line.
+type Recipe struct {
+	Name     string
+	Summary  string
+	Params   []string
+	Defaults DemoParams
+	Requires preflight.Requirements
+	Run      func(ctx context.Context, client *weaviate.Client, p DemoParams) error
+}
+
+// paramFlags registers the flag for each recipe parameter. The returned
+// function copies the parsed value into p.
+var paramFlags = map[string]func(fs *flag.FlagSet, p *DemoParams) func(){
+	"class": func(fs *flag.FlagSet, p *DemoParams) func() {
+		fs.StringVar(&p.Class, "class", p.Class, "class name or alias")
+		return func() {}
+	},
+	"concept": func(fs *flag.FlagSet, p *DemoParams) func() {
+		concepts := cli.NewStringList(p.Concepts...)
+		fs.Var(concepts, "concept", "nearText concept, repeatable")
+		return func() { p.Concepts = concepts.Values() }
+	},
+	"distance": func(fs *flag.FlagSet, p *DemoParams) func() {
+		fs.Float64Var(&p.Distance, "distance", p.Distance, "maximum vector distance, 0 for none")
+		return func() {}
+	},
+	"limit": func(fs *flag.FlagSet, p *DemoParams) func() {
+		fs.IntVar(&p.Limit, "limit", p.Limit, "maximum number of results")
+		return func() {}
+	},
+	"prompt": func(fs *flag.FlagSet, p *DemoParams) func() {
+		fs.StringVar(&p.Prompt, "prompt", p.Prompt, "generative prompt, {property} placeholders allowed")
+		return func() {}
+	},
+	"question": func(fs *flag.FlagSet, p *DemoParams) func() {
+		fs.StringVar(&p.Question, "question", p.Question, "question for the qna module")
+		return func() {}
+	},
+	"id": func(fs *flag.FlagSet, p *DemoParams) func() {
+		fs.StringVar(&p.ID, "id", p.ID, "object id to search near")
+		return func() {}
+	},
+	"like": func(fs *flag.FlagSet, p *DemoParams) func() {
+		fs.StringVar(&p.Like, "like", p.Like, "Like pattern the question must match")
+		return func() {}
+	},
+	"min-points": func(fs *flag.FlagSet, p *DemoParams) func() {
+		fs.IntVar(&p.MinPoints, "min-points", p.MinPoints, "points must be greater than this")
+		return func() {}
+	},
+	"group-by": func(fs *flag.FlagSet, p *DemoParams) func() {
+		fs.StringVar(&p.GroupBy, "group-by", p.GroupBy, "property to group by")
+		return func() {}
+	},
+}
+
+// bind registers the recipe's parameters on fs. The returned function yields
+// the parameters once fs has been parsed.
+func (r *Recipe) bind(fs *flag.FlagSet) func() DemoParams {
+	p := r.Defaults
+	var apply []func()
+	for _, name := range r.Params {
+		apply = append(apply, paramFlags[name](fs, &p))
+	}
+	return func() DemoParams {
+		for _, fn := range apply {
+			fn()
+		}
+		return p
+	}
+}
+
+var recipes = []*Recipe{
+	{
+		Name:     "major-cities",
+		Summary:  "WikiCity nearText search",
+		Params:   []string{"class", "concept", "distance", "limit"},
+		Defaults: DemoParams{Class: "WikiCity", Concepts: []string{"Major European city"}, Limit: 3},
+		Requires: preflight.Requirements{Modules: []string{"text2vec-*"}},
+		Run:      DemoMajorCities,
+	},
+	{
+		Name:     "london-olympics",
+		Summary:  "WikiCity question answering with ask",
+		Params:   []string{"class", "question", "limit"},
+		Defaults: DemoParams{Class: "WikiCity", Question: "When was the London Olympics?", Limit: 1},
+		Requires: preflight.Requirements{Modules: []string{"qna-openai"}},
+		Run:      DemoLondonOlympics,
+	},
+	{
+		Name:     "london-olympics-raw",
+		Summary:  "london-olympics as a raw GraphQL query",
+		Params:   []string{"class", "question", "limit"},
+		Defaults: DemoParams{Class: "WikiCity", Question: "When was the London Olympics?", Limit: 1},
+		Requires: preflight.Requirements{Modules: []string{"qna-openai"}},
+		Run:      DemoLondonOlympicsRaw,
+	},
+	{
+		Name:    "tweet",
+		Summary: "WikiCity generative search writing a tweet per city",
+		Params:  []string{"class", "concept", "distance", "limit", "prompt"},
+		Defaults: DemoParams{
+			Class:    "WikiCity",
+			Concepts: []string{"Popular Southeast Asian tourist destination"},
+			Limit:    3,
+			Prompt:   "Write a tweet with a potentially surprising fact from {wiki_summary}",
+		},
+		Requires: preflight.Requirements{
+			Modules:  []string{"text2vec-*", "generative-openai"},
+			Features: []preflight.Feature{preflight.FeatureGenerative},
+		},
+		Run: DemoTweet,
+	},
+	{
+		Name:    "tweet-raw",
+		Summary: "tweet as a raw GraphQL query",
+		Params:  []string{"class", "concept", "limit", "prompt"},
+		Defaults: DemoParams{
+			Class:    "WikiCity",
+			Concepts: []string{"Popular Southeast Asian tourist destination"},
+			Limit:    3,
+			Prompt:   "Write a tweet with a potentially surprising fact from {wiki_summary}",
+		},
+		Requires: preflight.Requirements{
+			Modules:  []string{"text2vec-*", "generative-openai"},
+			Features: []preflight.Feature{preflight.FeatureGenerative},
+		},
+		Run: DemoTweetRaw,
+	},
+	{
+		Name:     "jeopardy-near-text",
+		Summary:  "JeopardyQuestion nearText search",
+		Params:   []string{"class", "concept", "distance", "limit"},
+		Defaults: DemoParams{Class: "JeopardyQuestion", Concepts: []string{"Intergalactic travel"}, Limit: 2},
+		Requires: preflight.Requirements{Modules: []string{"text2vec-*"}},
+		Run:      DemoJeopardyQuestionNearText,
+	},
+	{
+		Name:     "jeopardy-near-object",
+		Summary:  "JeopardyQuestion nearObject search",
+		Params:   []string{"class", "id", "distance", "limit"},
+		Defaults: DemoParams{Class: "JeopardyQuestion", ID: "c8f8176c-6f9b-5461-8ab3-f3c7ce8c2f5c", Limit: 2},
+		Run:      DemoJeopardyQuestionNearObject,
+	},
+	{
+		Name:     "jeopardy-near-text-where",
+		Summary:  "JeopardyQuestion nearText with a Like filter",
+		Params:   []string{"class", "concept", "distance", "like", "limit"},
+		Defaults: DemoParams{Class: "JeopardyQuestion", Concepts: []string{"Intergalactic travel"}, Like: "*rocket*", Limit: 2},
+		Requires: preflight.Requirements{Modules: []string{"text2vec-*"}},
+		Run:      DemoJeopardyQuestionAggregateWithNearTextWhere,
+	},
+	{
+		Name:    "jeopardy-near-text-where-multiple",
+		Summary: "JeopardyQuestion nearText with Like and points filters",
+		Params:  []string{"class", "concept", "distance", "like", "min-points", "limit"},
+		Defaults: DemoParams{
+			Class:     "JeopardyQuestion",
+			Concepts:  []string{"Intergalactic travel"},
+			Like:      "*rocket*",
+			MinPoints: 400,
+			Limit:     2,
+		},
+		Requires: preflight.Requirements{Modules: []string{"text2vec-*"}},
+		Run:      DemoJeopardyQuestionAggregateWithNearTextWhereMultiple,
+	},
+	{
+		Name:     "jeopardy-aggregate",
+		Summary:  "top occurring JeopardyQuestion answers",
+		Params:   []string{"class", "limit"},
+		Defaults: DemoParams{Class: "JeopardyQuestion", Limit: 2},
+		Run:      DemoJeopardyQuestionAggregate,
+	},
+	{
+		Name:    "jeopardy-aggregate-grouped",
+		Summary: "JeopardyQuestion counts near a concept grouped by a property",
+		Params:  []string{"class", "concept", "distance", "group-by"},
+		Defaults: DemoParams{
+			Class:    "JeopardyQuestion",
+			Concepts: []string{"Intergalactic travel"},
+			Distance: 0.2,
+			GroupBy:  string(classes.JeopardyQuestionRound),
+		},
+		Requires: preflight.Requirements{Modules: []string{"text2vec-*"}},
+		Run:      DemoJeopardyQuestionAggregateWithNearTextGrouped,
+	},
+	{
+		Name:    "schema",
+		Summary: "print the schema",
+		Run: func(ctx context.Context, client *weaviate.Client, p DemoParams) error {
+			dump, err := academy.GetSchema(ctx, client)
+			if err != nil {
+				return err
+			}
+			return cli.Print(dump)
+		},
+	},
+	{
+		Name:    "meta",
+		Summary: "print server version and modules",
+		Run: func(ctx context.Context, client *weaviate.Client, p DemoParams) error {
+			meta, err := academy.GetMeta(ctx, client)
+			if err != nil {
+				return err
+			}
+			return cli.Print(meta)
+		},
+	},
+}
+
+// findRecipe looks up the recipe name for the command op.
+func findRecipe(op, name string) (*Recipe, error) {
+	for _, r := range recipes {
+		if r.Name == name {
+			return r, nil
+		}
+	}
+	return nil, academy.Errorf(academy.KindValidation, op, "unknown recipe %q, see `readonly-demo list`", name)
+}
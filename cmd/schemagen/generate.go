@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/weaviate/weaviate/entities/models"
+	"go/format"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// goTypes maps Weaviate data types to the Go types they decode into.
+// Cross-references, whose data type is a class name, are not listed.
+var goTypes = map[string]string{
+	"text":           "string",
+	"text[]":         "[]string",
+	"string":         "string",
+	"string[]":       "[]string",
+	"int":            "int",
+	"int[]":          "[]int",
+	"number":         "float64",
+	"number[]":       "[]float64",
+	"boolean":        "bool",
+	"boolean[]":      "[]bool",
+	"date":           "time.Time",
+	"date[]":         "[]time.Time",
+	"uuid":           "strfmt.UUID",
+	"uuid[]":         "[]strfmt.UUID",
+	"geoCoordinates": "*models.GeoCoordinates",
+	"phoneNumber":    "*models.PhoneNumber",
+	"blob":           "string",
+	"object":         "map[string]interface{}",
+	"object[]":       "[]map[string]interface{}",
+}
+
+type genClass struct {
+	Name       string
+	Properties []genProperty
+}
+
+type genProperty struct {
+	Name     string
+	GoName   string
+	Type     string
+	DataType string
+}
+
+// generate renders the structs and property constants of classes as a Go
+// source file of package pkg.
+func generate(pkg, source string, classes []*models.Class) ([]byte, error) {
+	data := struct {
+		Package string
+		Source  string
+		Classes []genClass
+		Imports []string
+	}{Package: pkg, Source: source}
+
+	imports := map[string]bool{
+		"example.com/weaviate-tutorial/pkg/academy": true,
+	}
+	for _, c := range classes {
+		gc := genClass{Name: goName(c.Class)}
+		for _, p := range c.Properties {
+			gp := genProperty{
+				Name:     p.Name,
+				GoName:   goName(p.Name),
+				DataType: strings.Join(p.DataType, ","),
+			}
+			gp.Type = goTypes[gp.DataType]
+			if gp.Type == "" {
+				// A reference to one or more classes.
+				gp.Type = "[]map[string]interface{}"
+			}
+			switch {
+			case strings.Contains(gp.Type, "time."):
+				imports["time"] = true
+			case strings.Contains(gp.Type, "strfmt."):
+				imports["github.com/go-openapi/strfmt"] = true
+			case strings.Contains(gp.Type, "models."):
+				imports["github.com/weaviate/weaviate/entities/models"] = true
+			}
+			gc.Properties = append(gc.Properties, gp)
+		}
+		data.Classes = append(data.Classes, gc)
+	}
+	for imp := range imports {
+		data.Imports = append(data.Imports, imp)
+	}
+	sort.Strings(data.Imports)
+
+	var buf bytes.Buffer
+	if err := fileTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w", err)
+	}
+	return src, nil
+}
+
+// initialisms are the words goName spells in capitals, as golint would.
+var initialisms = map[string]bool{
+	"API": true, "CSV": true, "HTML": true, "HTTP": true, "ID": true,
+	"JSON": true, "QNA": true, "SQL": true, "URI": true, "URL": true,
+	"UUID": true, "XML": true,
+}
+
+// goName turns a property or class name such as city_name into CityName,
+// writing initialisms in capitals: url becomes URL and article_url ArticleURL.
+// Words are split at underscores, dashes and lower to upper case changes, so
+// articleUrl becomes ArticleURL too.
+func goName(name string) string {
+	var (
+		b    strings.Builder
+		word []rune
+	)
+	flush := func() {
+		if len(word) == 0 {
+			return
+		}
+		w := string(word)
+		if upper := strings.ToUpper(w); initialisms[upper] {
+			b.WriteString(upper)
+		} else {
+			b.WriteRune(unicode.ToUpper(word[0]))
+			b.WriteString(string(word[1:]))
+		}
+		word = word[:0]
+	}
+	for i, r := range name {
+		switch {
+		case r == '_' || r == '-':
+			flush()
+		case unicode.IsUpper(r) && i > 0 && len(word) > 0 && !unicode.IsUpper(word[len(word)-1]):
+			flush()
+			word = append(word, r)
+		default:
+			word = append(word, r)
+		}
+	}
+	flush()
+	return b.String()
+}
+
+var fileTemplate = template.Must(template.New("file").Parse(`// Code generated by schemagen from {{.Source}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- range .Imports}}
+	"{{.}}"
+{{- end}}
+)
+{{range $c := .Classes}}
+// {{$c.Name}} is an object of the {{$c.Name}} class.
+type {{$c.Name}} struct {
+{{- range $c.Properties}}
+	{{.GoName}} {{.Type}} ` + "`" + `json:"{{.Name}},omitempty"` + "`" + `
+{{- end}}
+
+	academy.Additional ` + "`" + `json:"_additional"` + "`" + `
+}
+
+// {{$c.Name}}Property names a property of {{$c.Name}}.
+type {{$c.Name}}Property string
+
+// Properties of {{$c.Name}}.
+const (
+{{- range $c.Properties}}
+	{{$c.Name}}{{.GoName}} {{$c.Name}}Property = "{{.Name}}" // {{.DataType}}
+{{- end}}
+)
+
+// {{$c.Name}}Properties lists every property of {{$c.Name}} in schema order.
+var {{$c.Name}}Properties = []{{$c.Name}}Property{
+{{- range $c.Properties}}
+	{{$c.Name}}{{.GoName}},
+{{- end}}
+}
+
+// {{$c.Name}}Fields selects every property of {{$c.Name}}.
+var {{$c.Name}}Fields = Fields({{$c.Name}}Properties...)
+
+{{end}}`))
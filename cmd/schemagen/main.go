@@ -0,0 +1,152 @@
+package main
+
+// Generates Go structs and property name constants from the live schema, or
+// from class definition files with --from.
+
+import (
+	"context"
+	"example.com/weaviate-tutorial/internal/cli"
+	"example.com/weaviate-tutorial/internal/config"
+	"example.com/weaviate-tutorial/internal/output"
+	"example.com/weaviate-tutorial/internal/preflight"
+	"example.com/weaviate-tutorial/pkg/academy"
+	"github.com/weaviate/weaviate/entities/models"
+	"os"
+	"strings"
+)
+
+func main() {
+	fs := cli.NewFlagSet("schemagen")
+	conn := config.RegisterFlags(fs)
+	pfOptions := preflight.RegisterFlags(fs)
+	from := cli.NewStringList()
+	fs.Var(from, "from", "read class definition files or directories instead of the server, repeatable")
+	classNames := cli.NewStringList("JeopardyQuestion", "WikiCity", "Article")
+	fs.Var(classNames, "class", "comma separated classes to generate")
+	pkg := fs.String("package", "classes", "package name of the generated file")
+	out := fs.String("out", "", "output file, stdout if empty")
+	dump := fs.Bool("dump", false, "print the selected class definitions as YAML instead of generating code")
+	if err := cli.Parse(fs, os.Args[1:]); err != nil {
+		cli.Exit("schemagen", err)
+	}
+
+	ctx := context.Background()
+	var (
+		classes []*models.Class
+		source  string
+		err     error
+	)
+	if len(from.Values()) > 0 {
+		source = strings.Join(from.Values(), ", ")
+		classes, err = loadClasses(from.Values())
+	} else {
+		var settings config.Settings
+		settings, err = conn.Resolve("env")
+		if err == nil {
+			source = "the " + settings.Name + " schema"
+			classes, err = liveClasses(ctx, settings, *pfOptions)
+		}
+	}
+	if err != nil {
+		cli.Exit("schemagen", err)
+	}
+
+	selected, err := selectClasses(classes, classNames.Values())
+	if err != nil {
+		cli.Exit("schemagen", err)
+	}
+	if *dump {
+		cli.Exit("schemagen", output.Write(os.Stdout, output.YAML, selected))
+	}
+
+	src, err := generate(*pkg, source, selected)
+	if err != nil {
+		cli.Exit("schemagen", err)
+	}
+	if *out == "" {
+		_, err = os.Stdout.Write(src)
+	} else {
+		err = os.WriteFile(*out, src, 0o644)
+	}
+	cli.Exit("schemagen", err)
+}
+
+func liveClasses(ctx context.Context, settings config.Settings, pfOptions preflight.Options) ([]*models.Class, error) {
+	client, err := settings.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	pfOptions.Headers = settings.Headers
+	if err := preflight.New(client, settings.URL(), pfOptions).Require(ctx, preflight.Requirements{}); err != nil {
+		return nil, err
+	}
+	dump, err := academy.GetSchema(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	return dump.Classes, nil
+}
+
+func loadClasses(files []string) ([]*models.Class, error) {
+	var classes []*models.Class
+	for _, file := range files {
+		loaded, err := academy.LoadClasses(file)
+		if err != nil {
+			return nil, err
+		}
+		classes = append(classes, loaded...)
+	}
+	return classes, nil
+}
+
+// selectClasses returns the named classes in the order given. A class defined
+// in several sources, such as JeopardyQuestion locally and on edu-demo, gets
+// the union of their properties, so the generated type decodes either.
+func selectClasses(classes []*models.Class, names []string) ([]*models.Class, error) {
+	byName := map[string]*models.Class{}
+	for _, c := range classes {
+		prev, ok := byName[c.Class]
+		if !ok {
+			byName[c.Class] = c
+			continue
+		}
+		merged, err := mergeClass(prev, c)
+		if err != nil {
+			return nil, err
+		}
+		byName[c.Class] = merged
+	}
+	selected := make([]*models.Class, len(names))
+	for i, name := range names {
+		c, ok := byName[name]
+		if !ok {
+			return nil, academy.Errorf(academy.KindClassNotFound, "select class", "class %q not found", name)
+		}
+		selected[i] = c
+	}
+	return selected, nil
+}
+
+// mergeClass returns a copy of a with the properties of b it lacks appended.
+// A property both define must have the same data type.
+func mergeClass(a, b *models.Class) (*models.Class, error) {
+	merged := *a
+	merged.Properties = append([]*models.Property(nil), a.Properties...)
+	have := map[string]*models.Property{}
+	for _, p := range a.Properties {
+		have[p.Name] = p
+	}
+	for _, p := range b.Properties {
+		prev, ok := have[p.Name]
+		if !ok {
+			merged.Properties = append(merged.Properties, p)
+			continue
+		}
+		if strings.Join(prev.DataType, ",") != strings.Join(p.DataType, ",") {
+			return nil, academy.Errorf(academy.KindValidation, "select class",
+				"property %s.%s is %s in one definition and %s in another",
+				a.Class, p.Name, strings.Join(prev.DataType, ","), strings.Join(p.DataType, ","))
+		}
+	}
+	return &merged, nil
+}
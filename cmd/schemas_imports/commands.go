@@ -0,0 +1,767 @@
+package main
+
+import (
+	"context"
+	"example.com/weaviate-tutorial/internal/cli"
+	"example.com/weaviate-tutorial/internal/preflight"
+	"example.com/weaviate-tutorial/migrations"
+	"example.com/weaviate-tutorial/pkg/academy"
+	"example.com/weaviate-tutorial/schemas"
+	"flag"
+	"fmt"
+	"github.com/weaviate/weaviate-go-client/v4/weaviate"
+	"github.com/weaviate/weaviate/entities/models"
+	"os"
+	"strings"
+)
+
+func commands(client *weaviate.Client, pf *preflight.Checker, aliases *academy.Aliases) []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:    "hybrid",
+			Usage:   "--query [--alpha]",
+			Summary: "hybrid (BM25 + vector) search",
+			Run: func(ctx context.Context, args []string) error {
+				fs := cli.NewFlagSet("hybrid")
+				opts := searchFlags(fs, aliases,
+					[]string{"question", "answer"},
+					[]string{"question", "answer"},
+					[]string{"score", "explainScore"})
+				alpha := fs.Float64("alpha", 0.75, "vector search weight, 0 is pure BM25 and 1 pure vector; sent only when given")
+				if err := cli.Parse(fs, args); err != nil {
+					return err
+				}
+				var a *float32
+				if cli.IsSet(fs, "alpha") {
+					v := float32(*alpha)
+					a = &v
+				}
+				if err := pf.Require(ctx, preflight.Requirements{
+					Modules:  []string{"text2vec-*"},
+					Features: []preflight.Feature{preflight.FeatureHybrid},
+				}); err != nil {
+					return err
+				}
+				o, err := opts(ctx)
+				if err != nil {
+					return err
+				}
+				return JeopardyQuestionHybrid(ctx, client, o, a)
+			},
+		},
+		{
+			Name:    "bm25",
+			Usage:   "--query | tune --query",
+			Summary: "keyword search, properties may carry ^weights; tune compares BM25 settings",
+			Run: func(ctx context.Context, args []string) error {
+				fs := cli.NewFlagSet("bm25")
+				opts := searchFlags(fs, aliases,
+					[]string{"question^2", "answer"},
+					[]string{"question", "answer", "value", "round"},
+					[]string{"score", "id"})
+				if err := cli.Parse(fs, args); err != nil {
+					return err
+				}
+				if err := pf.Require(ctx, preflight.Requirements{
+					Features: []preflight.Feature{preflight.FeatureBM25},
+				}); err != nil {
+					return err
+				}
+				o, err := opts(ctx)
+				if err != nil {
+					return err
+				}
+				return JeopardyQuestionBM25(ctx, client, o)
+			},
+			Subcommands: []*cli.Command{
+				{
+					Name:    "tune",
+					Usage:   "--query [--k1] [--b] [--stopwords] [--keep]",
+					Summary: "compare the top-k of queries across BM25 settings on temporary copies of a class, one full copy per setting",
+					Run: func(ctx context.Context, args []string) error {
+						fs := cli.NewFlagSet("bm25 tune")
+						var f TuneFlags
+						fs.StringVar(&f.Class, "class", "JeopardyQuestion", "class name or alias to copy")
+						queries := cli.NewStringList()
+						fs.Var(queries, "query", "search string, repeatable")
+						fs.StringVar(&f.QueryFile, "queries", "", "file with one search string per line")
+						// Every combination of the values below is a full copy of
+						// the class, so the default stays at two.
+						k1 := cli.NewStringList("0.5", "2")
+						fs.Var(k1, "k1", "comma separated k1 values to try; each k1 x b x stopwords combination copies the whole class")
+						b := cli.NewStringList()
+						fs.Var(b, "b", "comma separated b values to try, 0 turns off length normalisation (default the class's b)")
+						stopwords := cli.NewStringList()
+						fs.Var(stopwords, "stopwords", "comma separated stopword presets to try, en or none (default the class's)")
+						properties := cli.NewStringList("question", "answer")
+						fs.Var(properties, "properties", "comma separated properties to search, e.g. question^2,answer")
+						fs.StringVar(&f.Label, "label", "question", "property shown for each hit")
+						fs.IntVar(&f.Limit, "limit", 5, "top-k to compare")
+						fs.IntVar(&f.BatchSize, "batch-size", 100, "objects per read and write batch while copying")
+						fs.BoolVar(&f.Keep, "keep", false, "keep the variant classes instead of deleting them")
+						if err := cli.Parse(fs, args); err != nil {
+							return err
+						}
+						f.Queries, f.K1, f.B, f.Stopwords, f.Properties = queries.Values(), k1.Values(), b.Values(), stopwords.Values(), properties.Values()
+						if len(f.Queries) == 0 && f.QueryFile == "" {
+							fs.Usage()
+							return cli.ErrUsage
+						}
+						if f.BatchSize <= 0 {
+							fmt.Fprintln(fs.Output(), "--batch-size must be at least 1")
+							fs.Usage()
+							return cli.ErrUsage
+						}
+						if err := pf.Require(ctx, preflight.Requirements{
+							Features: []preflight.Feature{preflight.FeatureBM25, preflight.FeatureCursor},
+						}); err != nil {
+							return err
+						}
+						class, err := aliases.Resolve(ctx, f.Class)
+						if err != nil {
+							return err
+						}
+						f.Class = class
+						return BM25Tune(ctx, client, f)
+					},
+				},
+			},
+		},
+		{
+			Name:    "schema",
+			Summary: "create, compare, delete or print classes",
+			Subcommands: []*cli.Command{
+				{
+					Name:    "create",
+					Usage:   "[--class] [--ensure|--recreate]",
+					Summary: "create a class from its embedded definition",
+					Run: func(ctx context.Context, args []string) error {
+						fs := cli.NewFlagSet("schema create")
+						className := fs.String("class", "JeopardyQuestion", "class to create, one of the definitions in schemas/")
+						opts := createFlags(fs)
+						if err := cli.Parse(fs, args); err != nil {
+							return err
+						}
+						class, err := schemas.Class(*className)
+						if err != nil {
+							return err
+						}
+						if err := pf.RequireClasses(ctx, class); err != nil {
+							return err
+						}
+						return SchemaCreate(ctx, client, class, *opts)
+					},
+				},
+				{
+					Name:    "apply",
+					Usage:   "[--file] [--ensure|--recreate]",
+					Summary: "create the classes defined in YAML or JSON files",
+					Run: func(ctx context.Context, args []string) error {
+						fs := cli.NewFlagSet("schema apply")
+						files := cli.NewStringList()
+						fs.Var(files, "file", "class definition file or directory, repeatable (default the definitions embedded from schemas/)")
+						opts := createFlags(fs)
+						if err := cli.Parse(fs, args); err != nil {
+							return err
+						}
+						classes, err := loadClasses(files.Values())
+						if err != nil {
+							return err
+						}
+						if err := pf.RequireClasses(ctx, classes...); err != nil {
+							return err
+						}
+						return SchemaApply(ctx, client, classes, *opts)
+					},
+				},
+				{
+					Name:    "diff",
+					Usage:   "[--file] [--apply]",
+					Summary: "compare class files with the live schema, optionally applying what can change in place",
+					Run: func(ctx context.Context, args []string) error {
+						fs := cli.NewFlagSet("schema diff")
+						files := cli.NewStringList()
+						fs.Var(files, "file", "class definition file or directory, repeatable (default the definitions embedded from schemas/)")
+						apply := fs.Bool("apply", false, "create missing classes and properties; refused if a change needs a reindex")
+						if err := cli.Parse(fs, args); err != nil {
+							return err
+						}
+						classes, err := loadClasses(files.Values())
+						if err != nil {
+							return err
+						}
+						// Creating classes needs their modules, comparing does not.
+						if *apply {
+							err = pf.RequireClasses(ctx, classes...)
+						} else {
+							err = pf.Require(ctx, preflight.Requirements{})
+						}
+						if err != nil {
+							return err
+						}
+						return SchemaDiff(ctx, client, classes, *apply)
+					},
+				},
+				{
+					Name:    "delete",
+					Usage:   "--class [--yes] [--dry-run] [--export]",
+					Summary: "delete a class and all of its objects after confirmation",
+					Run: func(ctx context.Context, args []string) error {
+						fs := cli.NewFlagSet("schema delete")
+						className := fs.String("class", "", "class or alias to delete")
+						var opts DeleteOptions
+						fs.BoolVar(&opts.Yes, "yes", false, "do not ask for confirmation")
+						fs.BoolVar(&opts.DryRun, "dry-run", false, "only show what would be deleted")
+						fs.StringVar(&opts.Export, "export", "", "first write the class and its objects with vectors to this JSONL file")
+						if err := cli.Parse(fs, args); err != nil {
+							return err
+						}
+						if *className == "" {
+							fs.Usage()
+							return cli.ErrUsage
+						}
+						if err := pf.Require(ctx, preflight.Requirements{}); err != nil {
+							return err
+						}
+						class, err := aliases.Resolve(ctx, *className)
+						if err != nil {
+							return err
+						}
+						return SchemaDelete(ctx, client, class, opts)
+					},
+				},
+				{
+					Name:    "restore",
+					Usage:   "--file [--batch-size]",
+					Summary: "recreate a class from a `schema delete --export` file",
+					Run: func(ctx context.Context, args []string) error {
+						fs := cli.NewFlagSet("schema restore")
+						file := fs.String("file", "", "export file")
+						batchSize := fs.Int("batch-size", 100, "objects per batch")
+						if err := cli.Parse(fs, args); err != nil {
+							return err
+						}
+						if *file == "" {
+							fs.Usage()
+							return cli.ErrUsage
+						}
+						if err := pf.Require(ctx, preflight.Requirements{}); err != nil {
+							return err
+						}
+						return SchemaRestore(ctx, client, *file, *batchSize)
+					},
+				},
+				{
+					Name:    "get",
+					Summary: "print the schema",
+					Run: func(ctx context.Context, args []string) error {
+						if err := cli.Parse(cli.NewFlagSet("schema get"), args); err != nil {
+							return err
+						}
+						if err := pf.Require(ctx, preflight.Requirements{}); err != nil {
+							return err
+						}
+						dump, err := academy.GetSchema(ctx, client)
+						if err != nil {
+							return err
+						}
+						return cli.Print(dump)
+					},
+				},
+			},
+		},
+		{
+			Name:    "vector-index",
+			Summary: "change the vector index of a class",
+			Subcommands: []*cli.Command{
+				{
+					Name:    "update",
+					Usage:   "--class [--ef] [--pq] [--dry-run]",
+					Summary: "change the mutable HNSW and PQ settings and print them before and after",
+					Run: func(ctx context.Context, args []string) error {
+						fs := cli.NewFlagSet("vector-index update")
+						className := fs.String("class", "JeopardyQuestion", "class name or alias")
+						dryRun := fs.Bool("dry-run", false, "only show the configuration the update would produce")
+						fs.Int("ef", -1, "query time candidate list size, -1 for dynamic")
+						fs.Int("dynamic-ef-min", 100, "lower bound of the dynamic ef")
+						fs.Int("dynamic-ef-max", 500, "upper bound of the dynamic ef")
+						fs.Int("dynamic-ef-factor", 8, "dynamic ef as a multiple of the limit")
+						fs.Int("flat-search-cutoff", 40000, "filter matches below which a flat search is used")
+						fs.Int64("vector-cache-max-objects", 1e12, "vectors kept in memory")
+						fs.Bool("pq", true, "enable product quantization; the class should already hold the training objects")
+						fs.Int("pq-segments", 0, "PQ segments, 0 lets the server choose")
+						fs.Int("pq-centroids", 256, "PQ centroids per segment, at most 256")
+						fs.Int("pq-training-limit", 100000, "objects used to train PQ")
+						fs.String("pq-encoder", "kmeans", "PQ encoder, kmeans or tile")
+						if err := cli.Parse(fs, args); err != nil {
+							return err
+						}
+						settings := vectorIndexSettings(fs)
+						if len(settings) == 0 {
+							fmt.Fprintln(fs.Output(), "no setting to change")
+							fs.Usage()
+							return cli.ErrUsage
+						}
+						reqs := preflight.Requirements{}
+						if _, ok := settings["pq"]; ok {
+							reqs.Features = append(reqs.Features, preflight.FeaturePQ)
+						}
+						if err := pf.Require(ctx, reqs); err != nil {
+							return err
+						}
+						class, err := aliases.Resolve(ctx, *className)
+						if err != nil {
+							return err
+						}
+						return VectorIndexUpdate(ctx, client, class, settings, *dryRun)
+					},
+				},
+			},
+		},
+		{
+			Name:    "migrate",
+			Summary: "apply, list or revert the numbered schema migrations",
+			Subcommands: []*cli.Command{
+				{
+					Name:    "status",
+					Usage:   "[--dir]",
+					Summary: "list the migrations and whether they are applied",
+					Run: func(ctx context.Context, args []string) error {
+						fs := cli.NewFlagSet("migrate status")
+						load := migrationFlags(fs)
+						if err := cli.Parse(fs, args); err != nil {
+							return err
+						}
+						migs, err := load()
+						if err != nil {
+							return err
+						}
+						if err := pf.Require(ctx, preflight.Requirements{Features: []preflight.Feature{preflight.FeatureCursor}}); err != nil {
+							return err
+						}
+						return MigrateStatus(ctx, academy.Migrator{Client: client, Aliases: aliases}, migs)
+					},
+				},
+				{
+					Name:    "up",
+					Usage:   "[--to] [--dir]",
+					Summary: "apply the pending migrations in order",
+					Run: func(ctx context.Context, args []string) error {
+						fs := cli.NewFlagSet("migrate up")
+						load := migrationFlags(fs)
+						to := fs.Int("to", 0, "stop after this version, default all")
+						if err := cli.Parse(fs, args); err != nil {
+							return err
+						}
+						migs, err := load()
+						if err != nil {
+							return err
+						}
+						if err := pf.Require(ctx, preflight.Requirements{Features: []preflight.Feature{preflight.FeatureCursor}}); err != nil {
+							return err
+						}
+						m := academy.Migrator{Client: client, Aliases: aliases}
+						plan, err := m.UpPlan(ctx, migs, *to)
+						if err != nil {
+							return err
+						}
+						var classes []*models.Class
+						for _, mig := range plan {
+							classes = append(classes, mig.Classes()...)
+						}
+						if err := pf.RequireClasses(ctx, classes...); err != nil {
+							return err
+						}
+						return MigrateUp(ctx, m, migs, *to)
+					},
+				},
+				{
+					Name:    "down",
+					Usage:   "[--steps] [--yes] [--dir]",
+					Summary: "revert the latest applied migrations after confirmation",
+					Run: func(ctx context.Context, args []string) error {
+						fs := cli.NewFlagSet("migrate down")
+						load := migrationFlags(fs)
+						steps := fs.Int("steps", 1, "number of migrations to revert")
+						yes := fs.Bool("yes", false, "do not ask for confirmation")
+						if err := cli.Parse(fs, args); err != nil {
+							return err
+						}
+						if *steps < 1 {
+							fmt.Fprintln(fs.Output(), "--steps must be at least 1")
+							fs.Usage()
+							return cli.ErrUsage
+						}
+						migs, err := load()
+						if err != nil {
+							return err
+						}
+						if err := pf.Require(ctx, preflight.Requirements{Features: []preflight.Feature{preflight.FeatureCursor}}); err != nil {
+							return err
+						}
+						return MigrateDown(ctx, academy.Migrator{Client: client, Aliases: aliases}, migs, *steps, *yes)
+					},
+				},
+			},
+		},
+		{
+			Name:    "class",
+			Summary: "copy classes",
+			Subcommands: []*cli.Command{
+				{
+					Name:    "clone",
+					Usage:   "--from --to [--file] [--vectorizer] [--tokenization prop=value]",
+					Summary: "create a class with a modified definition and copy all objects into it",
+					Run: func(ctx context.Context, args []string) error {
+						fs := cli.NewFlagSet("class clone")
+						var f CloneFlags
+						fs.StringVar(&f.From, "from", "", "source class or alias")
+						fs.StringVar(&f.To, "to", "", "target class, must not exist")
+						fs.StringVar(&f.File, "file", "", "target definition file, default the live source definition")
+						fs.StringVar(&f.Vectorizer, "vectorizer", "", "target vectorizer")
+						tokenization := cli.NewStringList()
+						fs.Var(tokenization, "tokenization", "property=tokenization for the target, repeatable")
+						fs.IntVar(&f.BatchSize, "batch-size", 100, "objects per read and write batch")
+						fs.BoolVar(&f.KeepIDs, "keep-ids", true, "keep the object IDs")
+						fs.BoolVar(&f.KeepVectors, "keep-vectors", false, "copy the vectors instead of vectorizing again")
+						if err := cli.Parse(fs, args); err != nil {
+							return err
+						}
+						f.Tokenization = tokenization.Values()
+						if f.From == "" || f.To == "" {
+							fs.Usage()
+							return cli.ErrUsage
+						}
+						if f.BatchSize <= 0 {
+							fmt.Fprintln(fs.Output(), "--batch-size must be at least 1")
+							fs.Usage()
+							return cli.ErrUsage
+						}
+						if err := pf.Require(ctx, preflight.Requirements{Features: []preflight.Feature{preflight.FeatureCursor}}); err != nil {
+							return err
+						}
+						from, err := aliases.Resolve(ctx, f.From)
+						if err != nil {
+							return err
+						}
+						f.From = from
+						target, err := cloneTarget(ctx, client, f)
+						if err != nil {
+							return err
+						}
+						if err := pf.RequireClasses(ctx, target); err != nil {
+							return err
+						}
+						return ClassClone(ctx, client, target, f)
+					},
+				},
+			},
+		},
+		{
+			Name:    "alias",
+			Summary: "list, switch or roll back class aliases",
+			Subcommands: []*cli.Command{
+				{
+					Name:    "list",
+					Summary: "print every alias and the class it points to",
+					Run: func(ctx context.Context, args []string) error {
+						if err := cli.Parse(cli.NewFlagSet("alias list"), args); err != nil {
+							return err
+						}
+						list, err := aliases.List(ctx)
+						if err != nil {
+							return err
+						}
+						return cli.Print(list)
+					},
+				},
+				{
+					Name:    "switch",
+					Usage:   "--name --class",
+					Summary: "point an alias at a class, creating the alias if needed",
+					Run: func(ctx context.Context, args []string) error {
+						fs := cli.NewFlagSet("alias switch")
+						name := fs.String("name", "", "alias, e.g. questions")
+						className := fs.String("class", "", "class the alias should point to")
+						if err := cli.Parse(fs, args); err != nil {
+							return err
+						}
+						if *name == "" || *className == "" {
+							fs.Usage()
+							return cli.ErrUsage
+						}
+						if err := pf.Require(ctx, preflight.Requirements{}); err != nil {
+							return err
+						}
+						return AliasSwitch(ctx, client, aliases, *name, *className)
+					},
+				},
+				{
+					Name:    "rollback",
+					Usage:   "--name",
+					Summary: "point an alias back at its previous class",
+					Run: func(ctx context.Context, args []string) error {
+						fs := cli.NewFlagSet("alias rollback")
+						name := fs.String("name", "", "alias to roll back")
+						if err := cli.Parse(fs, args); err != nil {
+							return err
+						}
+						if *name == "" {
+							fs.Usage()
+							return cli.ErrUsage
+						}
+						if err := pf.Require(ctx, preflight.Requirements{}); err != nil {
+							return err
+						}
+						alias, err := aliases.Rollback(ctx, *name)
+						if err != nil {
+							return err
+						}
+						return cli.Print(alias)
+					},
+				},
+			},
+		},
+		{
+			Name:    "meta",
+			Summary: "print server version and modules",
+			Run: func(ctx context.Context, args []string) error {
+				if err := cli.Parse(cli.NewFlagSet("meta"), args); err != nil {
+					return err
+				}
+				if err := pf.Require(ctx, preflight.Requirements{}); err != nil {
+					return err
+				}
+				meta, err := academy.GetMeta(ctx, client)
+				if err != nil {
+					return err
+				}
+				return cli.Print(meta)
+			},
+		},
+		{
+			Name:    "import",
+			Usage:   "[--file] [--class] [--resume] [--checkpoint] [--batch-size] [--workers] [--queue] [--retries] [--backoff] [--dead-letter]",
+			Summary: "stream Jeopardy questions from a JSON file in concurrent batches",
+			Run: func(ctx context.Context, args []string) error {
+				fs := cli.NewFlagSet("import")
+				file := fs.String("file", "./jeopardy_100.json", "JSON array of questions")
+				className := fs.String("class", "JeopardyQuestion", "target class or alias")
+				resume := fs.Bool("resume", false, "continue an import that stopped early from its checkpoint")
+				checkpoint := fs.String("checkpoint", "", "file tracking the acknowledged batches (default <file>.checkpoint.json)")
+				opts := cli.ImportFlags(fs)
+				deadLetter := fs.String("dead-letter", "deadletter.jsonl", "file for the objects that finally fail, empty to discard them")
+				if err := cli.Parse(fs, args); err != nil {
+					return err
+				}
+				class, err := aliases.Resolve(ctx, *className)
+				if err != nil {
+					return err
+				}
+				if err := requireClass(ctx, client, pf, class); err != nil {
+					return err
+				}
+				if *checkpoint == "" {
+					*checkpoint = *file + ".checkpoint.json"
+				}
+				return JeopardyQuestionsImport(ctx, client, class, *file, *checkpoint, *deadLetter, *resume, *opts)
+			},
+			Subcommands: []*cli.Command{
+				{
+					Name:    "retry",
+					Usage:   "--from [--dead-letter] [--batch-size] [--workers] [--queue] [--retries] [--backoff]",
+					Summary: "import the objects of a dead-letter file again",
+					Run: func(ctx context.Context, args []string) error {
+						fs := cli.NewFlagSet("import retry")
+						from := fs.String("from", "", "dead-letter file written by an import")
+						opts := cli.ImportFlags(fs)
+						deadLetter := fs.String("dead-letter", "", "file for the objects that fail again (default <from>.retry.jsonl)")
+						if err := cli.Parse(fs, args); err != nil {
+							return err
+						}
+						if *from == "" {
+							fs.Usage()
+							return cli.ErrUsage
+						}
+						if *deadLetter == "" {
+							*deadLetter = strings.TrimSuffix(*from, ".jsonl") + ".retry.jsonl"
+						}
+						if err := pf.Require(ctx, preflight.Requirements{}); err != nil {
+							return err
+						}
+						return ImportRetry(ctx, client, *from, *deadLetter, *opts)
+					},
+				},
+			},
+		},
+		{
+			Name:    "articles",
+			Usage:   "[--count] [--class] [--retries] [--backoff] [--dead-letter]",
+			Summary: "batch import generated Article objects",
+			Run: func(ctx context.Context, args []string) error {
+				fs := cli.NewFlagSet("articles")
+				count := fs.Int("count", 5, "number of articles")
+				className := fs.String("class", "Article", "target class or alias")
+				var opts academy.ImportOptions
+				cli.BackoffFlags(fs, &opts.Backoff)
+				deadLetter := fs.String("dead-letter", "deadletter.jsonl", "file for the objects that finally fail, empty to discard them")
+				if err := cli.Parse(fs, args); err != nil {
+					return err
+				}
+				class, err := aliases.Resolve(ctx, *className)
+				if err != nil {
+					return err
+				}
+				if err := requireClass(ctx, client, pf, class); err != nil {
+					return err
+				}
+				return BatchImport(ctx, client, class, *count, *deadLetter, opts)
+			},
+		},
+		{
+			Name:    "dummy",
+			Summary: "create or delete a single TestClass object",
+			Subcommands: []*cli.Command{
+				{
+					Name:    "create",
+					Summary: "create a dummy object",
+					Run: func(ctx context.Context, args []string) error {
+						fs := cli.NewFlagSet("dummy create")
+						className := fs.String("class", "TestClass", "class name")
+						if err := cli.Parse(fs, args); err != nil {
+							return err
+						}
+						if err := pf.Require(ctx, preflight.Requirements{}); err != nil {
+							return err
+						}
+						return DummyCreate(ctx, client, *className)
+					},
+				},
+				{
+					Name:    "delete",
+					Usage:   "--id",
+					Summary: "delete an object by id",
+					Run: func(ctx context.Context, args []string) error {
+						fs := cli.NewFlagSet("dummy delete")
+						className := fs.String("class", "TestClass", "class name")
+						id := fs.String("id", "3b2dd386-7700-434f-80bf-d3472a913186", "object id")
+						if err := cli.Parse(fs, args); err != nil {
+							return err
+						}
+						if err := pf.Require(ctx, preflight.Requirements{}); err != nil {
+							return err
+						}
+						return DummyDelete(ctx, client, *className, *id)
+					},
+				},
+			},
+		},
+	}
+}
+
+// requireClass runs the preflight for importing into the live className. A
+// class that does not exist yet is created by auto-schema on import, so it
+// then only needs a text2vec vectorizer.
+func requireClass(ctx context.Context, client *weaviate.Client, pf *preflight.Checker, className string) error {
+	if err := pf.Require(ctx, preflight.Requirements{}); err != nil {
+		return err
+	}
+	exists, err := academy.ClassExists(ctx, client, className)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return pf.Require(ctx, preflight.Requirements{Modules: []string{"text2vec-*"}})
+	}
+	class, err := academy.GetClass(ctx, client, className)
+	if err != nil {
+		return err
+	}
+	return pf.RequireClasses(ctx, class)
+}
+
+// vectorIndexPaths maps the vector-index update flags to vectorIndexConfig
+// settings.
+var vectorIndexPaths = map[string]string{
+	"ef":                       "ef",
+	"dynamic-ef-min":           "dynamicEfMin",
+	"dynamic-ef-max":           "dynamicEfMax",
+	"dynamic-ef-factor":        "dynamicEfFactor",
+	"flat-search-cutoff":       "flatSearchCutoff",
+	"vector-cache-max-objects": "vectorCacheMaxObjects",
+	"pq":                       "pq.enabled",
+	"pq-segments":              "pq.segments",
+	"pq-centroids":             "pq.centroids",
+	"pq-training-limit":        "pq.trainingLimit",
+	"pq-encoder":               "pq.encoder.type",
+}
+
+// vectorIndexSettings returns the vectorIndexConfig settings of the flags
+// given on the command line.
+func vectorIndexSettings(fs *flag.FlagSet) map[string]interface{} {
+	settings := map[string]interface{}{}
+	fs.Visit(func(f *flag.Flag) {
+		path, ok := vectorIndexPaths[f.Name]
+		if !ok {
+			return
+		}
+		m := settings
+		keys := strings.Split(path, ".")
+		for _, k := range keys[:len(keys)-1] {
+			next, ok := m[k].(map[string]interface{})
+			if !ok {
+				next = map[string]interface{}{}
+				m[k] = next
+			}
+			m = next
+		}
+		m[keys[len(keys)-1]] = f.Value.(flag.Getter).Get()
+	})
+	return settings
+}
+
+// migrationFlags registers --dir and returns a function loading the
+// migrations once fs has been parsed.
+func migrationFlags(fs *flag.FlagSet) func() ([]*academy.Migration, error) {
+	dir := fs.String("dir", "", "directory of <version>_<name>.yaml migrations, default the ones in migrations/")
+	return func() ([]*academy.Migration, error) {
+		if *dir == "" {
+			return migrations.Load()
+		}
+		return academy.LoadMigrations(os.DirFS(*dir))
+	}
+}
+
+// createFlags registers --ensure and --recreate.
+func createFlags(fs *flag.FlagSet) *CreateOptions {
+	opts := &CreateOptions{}
+	fs.BoolVar(&opts.Ensure, "ensure", false, "leave an existing class alone if it matches the definition, report drift otherwise")
+	fs.BoolVar(&opts.Recreate, "recreate", false, "delete and recreate an existing class that has drifted, losing its objects")
+	return opts
+}
+
+// searchFlags registers the flags shared by hybrid and bm25 with the given
+// defaults and returns a function building SearchOptions once fs has been
+// parsed, with the class name resolved through aliases.
+func searchFlags(fs *flag.FlagSet, aliases *academy.Aliases, properties, fields, additional []string) func(ctx context.Context) (SearchOptions, error) {
+	className := fs.String("class", "JeopardyQuestion", "class name or alias")
+	query := fs.String("query", "lake", "search string")
+	props := cli.NewStringList(properties...)
+	fs.Var(props, "properties", "comma separated properties to search, e.g. question^2,answer")
+	fieldList := cli.NewStringList(fields...)
+	fs.Var(fieldList, "fields", "comma separated properties to return")
+	additionalList := cli.NewStringList(additional...)
+	fs.Var(additionalList, "additional", "comma separated _additional fields, e.g. id,score,explainScore")
+	limit := fs.Int("limit", 3, "maximum number of results")
+	return func(ctx context.Context) (SearchOptions, error) {
+		class, err := aliases.Resolve(ctx, *className)
+		return SearchOptions{
+			ClassName:  class,
+			Query:      *query,
+			Properties: props.Values(),
+			Fields:     fieldList.Values(),
+			Additional: additionalList.Values(),
+			Limit:      *limit,
+		}, err
+	}
+}
@@ -4,57 +4,67 @@ package main
 
 import (
 	"context"
-	"crypto/md5"
-	"encoding/json"
+	"errors"
+	"example.com/weaviate-tutorial/internal/cli"
+	"example.com/weaviate-tutorial/internal/config"
+	"example.com/weaviate-tutorial/internal/preflight"
+	"example.com/weaviate-tutorial/pkg/academy"
+	"example.com/weaviate-tutorial/schemas"
 	"fmt"
-	"github.com/go-openapi/strfmt"
 	"github.com/weaviate/weaviate-go-client/v4/weaviate"
-	"github.com/weaviate/weaviate-go-client/v4/weaviate/graphql"
 	"github.com/weaviate/weaviate/entities/models"
-	"log"
+	"io"
 	"os"
+	"strconv"
 	"strings"
 )
 
-type JeopardyQuestion struct {
-	Round    string `json:"round"`
-	Value    int    `json:"value"`
-	Question string `json:"question"`
-	Answer   string `json:"answer"`
+// SearchOptions selects the class, query, searched properties and returned
+// fields of the keyword and hybrid searches.
+type SearchOptions struct {
+	ClassName  string
+	Query      string
+	Properties []string
+	Fields     []string
+	Additional []string
+	Limit      int
+}
+
+func (o SearchOptions) search() academy.Search {
+	return academy.Search{
+		ClassName:  o.ClassName,
+		Properties: o.Fields,
+		Additional: academy.Names(o.Additional...),
+		Limit:      o.Limit,
+	}
 }
 
 func main() {
-	headers := make(map[string]string)
-	headers["X-OpenAI-Api-Key"] = os.Getenv("OPENAI_APIKEY")
-	headers["X-OpenAI-Organization"] = os.Getenv("OPENAI_ORG")
-
-	cfg := weaviate.Config{
-		Host:   os.Getenv("WEAVIATE_HOST"),
-		Scheme: "http",
-		//Scheme: "https",
-		//AuthConfig: auth.ApiKey{Value: os.Getenv("WEAVIATE_API_KEY")},
-		Headers: headers,
-	}
-	client, err := weaviate.NewClient(cfg)
+	fs := cli.NewFlagSet("schemas_imports")
+	conn := config.RegisterFlags(fs)
+	pfOptions := preflight.RegisterFlags(fs)
+	args, err := cli.ParseGlobal(fs, commands(nil, nil, nil), os.Args[1:])
 	if err != nil {
-		panic(err)
+		cli.Exit("schemas_imports", err)
 	}
 
-	JeopardyQuestionHybrid(client)
-	//JeopardyQuestionBM25(client)
-	//JeopardyQuestionsImport(client)
-	//JeopardyQuestionSchemaCreate(client)
-	//BatchImport(client)
-	//DeleteClass(client, "Paragraph")
-	//DeleteClassArticle(client)
-	//CreateClassArticle(client)
-	//GetSchema(client)
-	//GetMeta(client)
-	//DummyDelete(client)
-	//DummyCreate(client)
+	settings, err := conn.Resolve("env")
+	if err != nil {
+		cli.Exit("schemas_imports", err)
+	}
+	client, err := settings.NewClient()
+	if err != nil {
+		cli.Exit("schemas_imports", err)
+	}
+
+	pfOptions.Headers = settings.Headers
+	pf := preflight.New(client, settings.URL(), *pfOptions)
+	aliases := academy.NewAliases(settings.AliasStore(client))
+
+	cli.Exit("schemas_imports", cli.Run(context.Background(), "schemas_imports", commands(client, pf, aliases), args))
 }
 
-func JeopardyQuestionHybrid(client *weaviate.Client) {
+func JeopardyQuestionHybrid(ctx context.Context, client *weaviate.Client, opts SearchOptions, alpha *float32) error {
 	/*
 		{
 			Get {
@@ -73,34 +83,16 @@ func JeopardyQuestionHybrid(client *weaviate.Client) {
 		}
 
 	*/
-	hybridBuilder := client.GraphQL().HybridArgumentBuilder().
-		WithQuery("lake").
-		WithProperties([]string{"question", "answer"})
-
-	res, err := client.GraphQL().Get().
-		WithClassName("JeopardyQuestion").
-		WithFields([]graphql.Field{
-			{Name: "question"},
-			{Name: "answer"},
-			{Name: "_additional", Fields: []graphql.Field{
-				{Name: "score"},
-				{Name: "explainScore"},
-			}},
-		}...).
-		WithHybrid(hybridBuilder).
-		WithLimit(3).
-		Do(context.Background())
-	if err != nil {
-		panic(err)
+	search := opts.search()
+	search.Hybrid = &academy.Hybrid{
+		Query:      opts.Query,
+		Properties: opts.Properties,
+		Alpha:      alpha,
 	}
-	b, err := json.MarshalIndent(res, "", "  ")
-	if err != nil {
-		log.Fatal(err)
-	}
-	fmt.Println(string(b))
+	return printSearch(ctx, client, search)
 }
 
-func JeopardyQuestionBM25(client *weaviate.Client) {
+func JeopardyQuestionBM25(ctx context.Context, client *weaviate.Client, opts SearchOptions) error {
 	/*
 		{
 			Get {
@@ -121,278 +113,606 @@ func JeopardyQuestionBM25(client *weaviate.Client) {
 		}
 
 	*/
-	bm25Builder := client.GraphQL().Bm25ArgBuilder().
-		WithQuery("lake").
-		WithProperties([]string{"question^2", "answer"}...)
-
-	res, err := client.GraphQL().Get().
-		WithClassName("JeopardyQuestion").
-		WithFields([]graphql.Field{
-			{Name: "question"},
-			{Name: "answer"},
-			{Name: "value"},
-			{Name: "round"},
-			{Name: "_additional", Fields: []graphql.Field{
-				{Name: "score"},
-				{Name: "id"},
-			}},
-		}...).
-		WithBM25(bm25Builder).
-		WithLimit(3).
-		Do(context.Background())
-	if err != nil {
-		panic(err)
-	}
-	b, err := json.MarshalIndent(res, "", "  ")
-	if err != nil {
-		log.Fatal(err)
-	}
-	fmt.Println(string(b))
+	search := opts.search()
+	search.BM25 = &academy.BM25{Query: opts.Query, Properties: opts.Properties}
+	return printSearch(ctx, client, search)
 }
 
-func generateWeaviateId(input string) strfmt.UUID {
-	input = strings.ToLower(input)
-	hash := md5.Sum([]byte(input))
-	u := fmt.Sprintf("%x-%x-%x-%x-%x", hash[0:4], hash[4:6], hash[6:8], hash[8:10], hash[10:])
-	return strfmt.UUID(u)
+func printSearch(ctx context.Context, client *weaviate.Client, search academy.Search) error {
+	res, err := search.Run(ctx, client)
+	if err := cli.Tolerate(err); err != nil {
+		return err
+	}
+	return cli.Print(res)
 }
 
-func JeopardyQuestionsImport(client *weaviate.Client) {
-	dat, err := os.ReadFile("./jeopardy_100.json")
+// JeopardyQuestionsImport streams the questions of file into className. On
+// Ctrl-C it stops reading and waits for the batches already read. The
+// checkpoint file follows the acknowledged batches until the import is
+// complete; with resume the import continues from it.
+func JeopardyQuestionsImport(ctx context.Context, client *weaviate.Client, className, file, checkpoint, deadLetter string, resume bool, opts academy.ImportOptions) error {
+	start, err := academy.NewCheckpoint(file, className)
 	if err != nil {
-		panic(err)
+		return err
+	}
+	if resume {
+		cp, err := academy.ReadCheckpoint(checkpoint)
+		if err != nil {
+			return err
+		}
+		if cp == nil {
+			fmt.Fprintf(os.Stderr, "no checkpoint in %s, starting from the beginning\n", checkpoint)
+		} else {
+			if err := cp.Resumes(file, className); err != nil {
+				return err
+			}
+			start = cp
+			fmt.Fprintf(os.Stderr, "resuming after object %d (byte %d)\n", cp.Index, cp.Offset)
+		}
 	}
 
-	var imports []JeopardyQuestion
-	err = json.Unmarshal(dat, &imports)
+	f, err := os.Open(file)
 	if err != nil {
-		log.Fatal(err)
-	}
-
-	className := "JeopardyQuestion"
-	var dataObjs []models.PropertySchema
-	for _, q := range imports {
-		fmt.Println(q.Question)
-		dataObjs = append(dataObjs, map[string]interface{}{
-			"round":    q.Round,
-			"value":    q.Value,
-			"question": q.Question,
-			"answer":   q.Answer,
-		})
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Seek(start.Offset, io.SeekStart); err != nil {
+		return err
 	}
 
-	batcher := client.Batch().ObjectsBatcher()
-	for _, dataObj := range dataObjs {
-		batcher.WithObjects(&models.Object{
-			Class:      className,
-			Properties: dataObj,
-			ID:         generateWeaviateId((dataObj.(map[string]interface{}))["question"].(string)),
-		})
+	// Progress runs one call at a time, after every batch.
+	cp := *start
+	var cperr error
+	opts.Start = start.Index
+	opts.Progress = func(s academy.ImportStats) {
+		cli.ImportProgress(s)
+		cp.Advance(*start, s)
+		if err := cp.Write(checkpoint); err != nil && cperr == nil {
+			cperr = err
+		}
+	}
+	dl := &cli.DeadLetterFile{Path: deadLetter, Append: resume}
+	err = runImport(ctx, client, dl, opts, func(ctx context.Context, im *academy.Importer) error {
+		return academy.ImportJeopardy(ctx, im, className, f, start.Offset)
+	})
+	if cperr != nil {
+		fmt.Fprintf(os.Stderr, "warning: cannot write checkpoint: %v\n", cperr)
 	}
+	// Rejected objects are in the dead-letter file, so only an import that
+	// stopped early is worth resuming.
+	if err == nil || academy.KindOf(err) == academy.KindPartialBatch {
+		if rerr := os.Remove(checkpoint); rerr != nil && !errors.Is(rerr, os.ErrNotExist) {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", rerr)
+		}
+		return err
+	}
+	if !cp.Updated.IsZero() {
+		fmt.Fprintf(os.Stderr, "objects up to %d acknowledged; continue with: import --resume --file %s\n", cp.Index, file)
+	}
+	return err
+}
 
-	res, err := batcher.Do(context.Background())
+// ImportRetry imports the objects of the dead-letter file from again, writing
+// those that still fail to deadLetter.
+func ImportRetry(ctx context.Context, client *weaviate.Client, from, deadLetter string, opts academy.ImportOptions) error {
+	if deadLetter == from {
+		return academy.Errorf(academy.KindValidation, "import retry", "--dead-letter must differ from --from")
+	}
+	f, err := os.Open(from)
 	if err != nil {
-		panic(err)
+		return err
 	}
+	defer f.Close()
+	dl := &cli.DeadLetterFile{Path: deadLetter}
+	return runImport(ctx, client, dl, opts, func(ctx context.Context, im *academy.Importer) error {
+		return academy.ReadDeadLetters(f, func(dl academy.DeadLetter) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			return im.Add(dl.Object)
+		})
+	})
+}
+
+// runImport is cli.RunImport, pointing at import retry for the objects that
+// failed.
+func runImport(ctx context.Context, client *weaviate.Client, dl *cli.DeadLetterFile, opts academy.ImportOptions, add func(context.Context, *academy.Importer) error) error {
+	err := cli.RunImport(ctx, client, dl, opts, add)
+	if dl.Lines > 0 {
+		fmt.Fprintf(os.Stderr, "import them again with: import retry --from %s\n", dl.Path)
+	}
+	return err
+}
+
+// CreateOptions selects what schema create and apply do with a class that
+// already exists: fail by default, keep it if it matches the definition with
+// Ensure, or replace it with Recreate.
+type CreateOptions struct {
+	Ensure   bool
+	Recreate bool
+}
+
+func SchemaCreate(ctx context.Context, client *weaviate.Client, class *models.Class, opts CreateOptions) error {
+	return createClasses(ctx, client, []*models.Class{class}, opts)
+}
 
-	for i, r := range res {
-		fmt.Printf("index %d: %s lastUpdateTimeUnix: %d\n", i, r.ID, r.LastUpdateTimeUnix)
-		if r.Result.Errors != nil {
-			for _, e := range r.Result.Errors.Error {
-				fmt.Printf("error at index %d: %s\n", i, e.Message)
+// SchemaApply creates classes, usually read from YAML or JSON files.
+func SchemaApply(ctx context.Context, client *weaviate.Client, classes []*models.Class, opts CreateOptions) error {
+	return createClasses(ctx, client, classes, opts)
+}
+
+func createClasses(ctx context.Context, client *weaviate.Client, classes []*models.Class, opts CreateOptions) error {
+	if !opts.Ensure && !opts.Recreate {
+		for _, class := range classes {
+			if err := academy.CreateClass(ctx, client, class); err != nil {
+				return err
 			}
+			fmt.Printf("created class %s\n", class.Class)
+		}
+		return nil
+	}
+
+	// Every drifted class is reported before failing.
+	var results []*academy.EnsureResult
+	var failed error
+	for _, class := range classes {
+		res, err := academy.EnsureClass(ctx, client, class, opts.Recreate)
+		if res != nil {
+			results = append(results, res)
 		}
+		if err != nil && failed == nil {
+			failed = err
+		}
+		if err != nil && (res == nil || res.Outcome != academy.EnsureDrifted) {
+			break
+		}
+	}
+	if err := cli.Print(results); err != nil {
+		return err
 	}
+	return failed
 }
 
-func JeopardyQuestionSchemaCreate(client *weaviate.Client) {
-	className := "JeopardyQuestion"
-	class := &models.Class{
-		Class: className,
-		Properties: []*models.Property{
-			{
-				Name:         "round",
-				DataType:     []string{"text"},
-				Tokenization: "field", // Jeopardy! not Jeopardy
-				ModuleConfig: map[string]any{
-					"text2vec-contextionary": map[string]any{
-						"skip": true,
-					},
-				},
-			},
-			{
-				Name:     "value",
-				DataType: []string{"int"},
-			},
-			{
-				Name:     "question",
-				DataType: []string{"text"},
-			},
-			{
-				Name:     "answer",
-				DataType: []string{"text"},
-			},
-		},
-		Vectorizer: "text2vec-contextionary",
-		ModuleConfig: map[string]any{
-			"text2vec-contextionary": map[string]any{
-				"skip":                  false,
-				"vectorizePropertyName": false,
-			},
-		},
-	}
-
-	err := client.Schema().ClassCreator().
-		WithClass(class).
-		Do(context.Background())
+// SchemaDiff compares the desired classes with the live schema. With apply
+// the additive changes are made; changes that need a reindex are never
+// applied and the reindex plan is printed instead.
+func SchemaDiff(ctx context.Context, client *weaviate.Client, want []*models.Class, apply bool) error {
+	have, err := academy.GetSchema(ctx, client)
+	if err != nil {
+		return err
+	}
+	diff, err := academy.DiffSchema(want, have.Classes)
 	if err != nil {
-		panic(err)
+		return err
+	}
+	if err := cli.Print(diff.Changes); err != nil {
+		return err
+	}
+	if plan := diff.ReindexPlan(); len(plan) > 0 {
+		fmt.Fprintln(os.Stderr, "incompatible changes, reindex plan:")
+		for i, step := range plan {
+			fmt.Fprintf(os.Stderr, "  %d. %s\n", i+1, step)
+		}
+	}
+	if !apply {
+		return nil
 	}
+	if err := academy.ApplyDiff(ctx, client, want, diff); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "applied %d change(s)\n", len(diff.Changes))
+	return nil
 }
 
-func BatchImport(client *weaviate.Client) {
-	className := "Article"
-	var dataObjs []models.PropertySchema
-	for i := 0; i < 5; i++ {
-		dataObjs = append(dataObjs, map[string]interface{}{
-			"title": fmt.Sprintf("Title %v", i),
-			"url":   fmt.Sprintf("https://example.com/article/%v", i),
-		})
+// DeleteOptions guard SchemaDelete. Export names a file that receives the
+// class definition and all objects, with vectors, before the class is
+// deleted; `schema restore` reads it back.
+type DeleteOptions struct {
+	Yes    bool
+	DryRun bool
+	Export string
+}
+
+func SchemaDelete(ctx context.Context, client *weaviate.Client, className string, opts DeleteOptions) error {
+	exists, err := academy.ClassExists(ctx, client, className)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return academy.Errorf(academy.KindClassNotFound, "delete class "+className, "class does not exist")
+	}
+	count, err := academy.Count(ctx, client, className)
+	if err != nil {
+		return err
 	}
 
-	batcher := client.Batch().ObjectsBatcher()
-	for _, dataObj := range dataObjs {
-		batcher.WithObjects(&models.Object{
-			Class:      className,
-			Properties: dataObj,
-		})
+	if opts.DryRun {
+		fmt.Printf("would delete class %s with %d object(s)\n", className, count)
+		if opts.Export != "" {
+			fmt.Printf("would export it to %s first\n", opts.Export)
+		}
+		return nil
+	}
+	if !opts.Yes {
+		ok, err := cli.Confirm(fmt.Sprintf("Delete class %s and its %d object(s)?", className, count))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Fprintln(os.Stderr, "not deleted")
+			return nil
+		}
+	}
+
+	if opts.Export != "" {
+		n, err := exportClass(ctx, client, className, opts.Export)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("exported class %s and %d object(s) to %s\n", className, n, opts.Export)
+	}
+	if err := academy.DeleteClass(ctx, client, className); err != nil {
+		return err
 	}
+	fmt.Printf("deleted class %s\n", className)
+	return nil
+}
 
-	res, err := batcher.Do(context.Background())
+func exportClass(ctx context.Context, client *weaviate.Client, className, file string) (int, error) {
+	f, err := os.Create(file)
 	if err != nil {
-		panic(err)
+		return 0, err
 	}
+	n, err := academy.ExportClass(ctx, client, className, f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return n, err
+}
 
-	//b, err := json.MarshalIndent(res, "", "  ")
-	//if err != nil {
-	//	log.Fatal(err)
-	//}
-	//fmt.Println(string(b))
+// SchemaRestore recreates a class exported by `schema delete --export`.
+func SchemaRestore(ctx context.Context, client *weaviate.Client, file string, batchSize int) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
 
-	for i, r := range res {
-		fmt.Printf("index %d: %s lastUpdateTimeUnix: %d\n", i, r.ID, r.LastUpdateTimeUnix)
-		if r.Result.Errors != nil {
-			for _, e := range r.Result.Errors.Error {
-				fmt.Printf("error at index %d: %s\n", i, e.Message)
+	res, err := academy.RestoreClass(ctx, client, f, batchSize)
+	if res != nil {
+		if err := cli.Print(res); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// CloneFlags select the source and target of `class clone`. The target
+// definition comes from File if given, otherwise from the live source class,
+// with Vectorizer and per-property Tokenization applied on top.
+type CloneFlags struct {
+	From         string
+	To           string
+	File         string
+	Vectorizer   string
+	Tokenization []string
+	BatchSize    int
+	KeepIDs      bool
+	KeepVectors  bool
+}
+
+// cloneTarget builds the definition of the target class.
+func cloneTarget(ctx context.Context, client *weaviate.Client, f CloneFlags) (*models.Class, error) {
+	var target *models.Class
+	if f.File != "" {
+		classes, err := academy.LoadClasses(f.File)
+		if err != nil {
+			return nil, err
+		}
+		if len(classes) != 1 {
+			return nil, academy.Errorf(academy.KindValidation, "clone", "%s defines %d classes, want one", f.File, len(classes))
+		}
+		target = classes[0]
+	} else {
+		live, err := academy.GetClass(ctx, client, f.From)
+		if err != nil {
+			return nil, err
+		}
+		target = live
+	}
+	target.Class = f.To
+
+	if f.Vectorizer != "" && f.Vectorizer != target.Vectorizer {
+		if f.KeepVectors {
+			return nil, academy.Errorf(academy.KindValidation, "clone",
+				"vectors from %s do not fit %s, drop --keep-vectors", target.Vectorizer, f.Vectorizer)
+		}
+		// Settings of the old vectorizer would be rejected by the server.
+		delete(moduleConfig(target.ModuleConfig), target.Vectorizer)
+		for _, p := range target.Properties {
+			delete(moduleConfig(p.ModuleConfig), target.Vectorizer)
+		}
+		target.Vectorizer = f.Vectorizer
+	}
+
+	for _, kv := range f.Tokenization {
+		name, tokenization, ok := strings.Cut(kv, "=")
+		if !ok {
+			return nil, academy.Errorf(academy.KindValidation, "clone", "--tokenization %q is not property=tokenization", kv)
+		}
+		found := false
+		for _, p := range target.Properties {
+			if p.Name == name {
+				p.Tokenization = tokenization
+				found = true
 			}
 		}
+		if !found {
+			return nil, academy.Errorf(academy.KindValidation, "clone", "class %s has no property %q", f.From, name)
+		}
 	}
+	return target, nil
 }
 
-func DeleteClass(client *weaviate.Client, className string) {
-	err := client.Schema().ClassDeleter().
-		WithClassName(className).
-		Do(context.Background())
-	if err != nil {
-		panic(err)
+func moduleConfig(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
+
+// ClassClone copies every object of f.From into the new class target.
+func ClassClone(ctx context.Context, client *weaviate.Client, target *models.Class, f CloneFlags) error {
+	res, err := academy.CloneClass(ctx, client, academy.CloneOptions{
+		Source:      f.From,
+		Target:      target,
+		BatchSize:   f.BatchSize,
+		KeepIDs:     f.KeepIDs,
+		KeepVectors: f.KeepVectors,
+	})
+	if res != nil {
+		if err := cli.Print(res); err != nil {
+			return err
+		}
 	}
+	return err
 }
 
-func DeleteClassArticle(client *weaviate.Client) {
-	className := "Article"
-	err := client.Schema().ClassDeleter().
-		WithClassName(className).
-		Do(context.Background())
-	if err != nil {
-		panic(err)
-	}
-}
-
-func CreateClassArticle(client *weaviate.Client) {
-	className := "Article"
-
-	class := &models.Class{
-		Class: className,
-		Properties: []*models.Property{
-			{
-				Name:     "title",
-				DataType: []string{"text"},
-			},
-			{
-				Name:     "body",
-				DataType: []string{"text"},
-			},
-			{
-				Name:     "url",
-				DataType: []string{"text"},
-				ModuleConfig: map[string]any{
-					"text2vec-openai": map[string]any{
-						"skip": true,
-					},
-				},
-			},
-		},
-		Vectorizer: "text2vec-openai",
+// loadClasses reads the class definition files, or returns the embedded
+// ones if there are none.
+func loadClasses(files []string) ([]*models.Class, error) {
+	if len(files) == 0 {
+		return schemas.Classes()
 	}
+	var classes []*models.Class
+	for _, file := range files {
+		loaded, err := academy.LoadClasses(file)
+		if err != nil {
+			return nil, err
+		}
+		classes = append(classes, loaded...)
+	}
+	return classes, nil
+}
 
-	err := client.Schema().ClassCreator().
-		WithClass(class).
-		Do(context.Background())
+// VectorIndexUpdate changes the vector index settings of className and
+// prints the configuration before and after.
+func VectorIndexUpdate(ctx context.Context, client *weaviate.Client, className string, settings map[string]interface{}, dryRun bool) error {
+	update, err := academy.UpdateVectorIndex(ctx, client, className, settings, dryRun)
 	if err != nil {
-		panic(err)
+		return err
 	}
+	if err := cli.Print(update); err != nil {
+		return err
+	}
+	if len(update.Changes) == 0 {
+		fmt.Fprintln(os.Stderr, "vector index already has these settings")
+	}
+	return nil
 }
 
-func DummyDelete(client *weaviate.Client) {
-	idToDelete := "3b2dd386-7700-434f-80bf-d3472a913186" // created by DummyCreate
-	err := client.Data().Deleter().
-		WithClassName("TestClass").
-		WithID(idToDelete).
-		Do(context.Background())
+// TuneFlags select the BM25 settings compared by BM25Tune. Every
+// combination of the K1, B and Stopwords values becomes a variant; an empty
+// list keeps the class's setting.
+type TuneFlags struct {
+	Class      string
+	Queries    []string
+	QueryFile  string
+	K1         []string
+	B          []string
+	Stopwords  []string
+	Properties []string
+	Label      string
+	Limit      int
+	BatchSize  int
+	Keep       bool
+}
+
+// BM25Tune prints the top-k of every query on the class and on one
+// temporary copy per BM25 setting.
+func BM25Tune(ctx context.Context, client *weaviate.Client, f TuneFlags) error {
+	queries := f.Queries
+	if f.QueryFile != "" {
+		data, err := os.ReadFile(f.QueryFile)
+		if err != nil {
+			return err
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				queries = append(queries, line)
+			}
+		}
+	}
+	variants, err := bm25Variants(f.K1, f.B, f.Stopwords)
+	if err != nil {
+		return err
+	}
+	rows, err := academy.TuneBM25(ctx, client, academy.TuneOptions{
+		Source:     f.Class,
+		Variants:   variants,
+		Queries:    queries,
+		Properties: f.Properties,
+		Label:      f.Label,
+		Limit:      f.Limit,
+		BatchSize:  f.BatchSize,
+		Keep:       f.Keep,
+	})
 	if err != nil {
-		panic(err)
+		return err
 	}
+	return cli.Print(rows)
 }
 
-func DummyCreate(client *weaviate.Client) {
-	res, err := client.Data().Creator().
-		WithClassName("TestClass").
-		WithProperties(map[string]interface{}{
-			"name": "dummy",
-		}).Do(context.Background())
+func bm25Variants(k1s, bs, presets []string) ([]academy.BM25Params, error) {
+	// nil keeps the source value when a flag has no values.
+	parse := func(flag string, values []string) ([]*float32, error) {
+		floats := []*float32{nil}
+		if len(values) > 0 {
+			floats = floats[:0]
+		}
+		for _, v := range values {
+			f, err := strconv.ParseFloat(v, 32)
+			if err != nil {
+				return nil, academy.Errorf(academy.KindValidation, "bm25 tune", "--%s %q is not a number", flag, v)
+			}
+			f32 := float32(f)
+			floats = append(floats, &f32)
+		}
+		return floats, nil
+	}
+	k1Values, err := parse("k1", k1s)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
-
-	b, err := json.MarshalIndent(res, "", "  ")
+	bValues, err := parse("b", bs)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
+	}
+	stopwords := []*models.StopwordConfig{nil}
+	if len(presets) > 0 {
+		stopwords = stopwords[:0]
+	}
+	for _, preset := range presets {
+		stopwords = append(stopwords, &models.StopwordConfig{Preset: preset})
+	}
+
+	var variants []academy.BM25Params
+	for _, k1 := range k1Values {
+		for _, b := range bValues {
+			for _, sw := range stopwords {
+				variants = append(variants, academy.BM25Params{K1: k1, B: b, Stopwords: sw})
+			}
+		}
 	}
-	fmt.Println(string(b))
+	return variants, nil
 }
 
-func GetSchema(client *weaviate.Client) {
-	schema, err := client.Schema().Getter().Do(context.Background())
+// MigrateStatus prints every migration with its state on the server.
+func MigrateStatus(ctx context.Context, m academy.Migrator, migs []*academy.Migration) error {
+	status, err := m.Status(ctx, migs)
 	if err != nil {
-		panic(err)
+		return err
 	}
+	return cli.Print(status)
+}
+
+// MigrateUp applies the pending migrations up to version to, 0 for all.
+func MigrateUp(ctx context.Context, m academy.Migrator, migs []*academy.Migration, to int) error {
+	applied, err := m.Up(ctx, migs, to)
+	for _, mig := range applied {
+		fmt.Printf("applied %s\n", mig)
+	}
+	if err == nil && len(applied) == 0 {
+		fmt.Println("no pending migrations")
+	}
+	return err
+}
 
-	b, err := json.MarshalIndent(schema, "", "  ")
+// MigrateDown reverts the latest steps migrations, asking first unless yes
+// is set.
+func MigrateDown(ctx context.Context, m academy.Migrator, migs []*academy.Migration, steps int, yes bool) error {
+	plan, err := m.DownPlan(ctx, migs, steps)
 	if err != nil {
-		log.Fatal(err)
+		return err
+	}
+	if len(plan) == 0 {
+		fmt.Println("no applied migrations")
+		return nil
+	}
+	if !yes {
+		names := make([]string, len(plan))
+		for i, mig := range plan {
+			names[i] = mig.String()
+		}
+		ok, err := cli.Confirm(fmt.Sprintf("Revert %s? Their down steps may delete classes and objects.", strings.Join(names, ", ")))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Fprintln(os.Stderr, "not reverted")
+			return nil
+		}
 	}
-	fmt.Println(string(b))
+	reverted, err := m.Down(ctx, migs, steps)
+	for _, mig := range reverted {
+		fmt.Printf("reverted %s\n", mig)
+	}
+	return err
 }
 
-func GetMeta(client *weaviate.Client) {
-	meta, err := client.Misc().MetaGetter().Do(context.Background())
+// AliasSwitch points the alias name at className, which must exist, and
+// prints the alias with the classes it pointed to before.
+func AliasSwitch(ctx context.Context, client *weaviate.Client, aliases *academy.Aliases, name, className string) error {
+	exists, err := academy.ClassExists(ctx, client, className)
 	if err != nil {
-		panic(err)
+		return err
+	}
+	if !exists {
+		return academy.Errorf(academy.KindClassNotFound, "switch alias "+name, "class %s does not exist", className)
 	}
+	alias, err := aliases.Switch(ctx, name, className)
+	if err != nil {
+		return err
+	}
+	return cli.Print(alias)
+}
+
+func BatchImport(ctx context.Context, client *weaviate.Client, className string, count int, deadLetter string, opts academy.ImportOptions) error {
+	return runImport(ctx, client, &cli.DeadLetterFile{Path: deadLetter}, opts, func(ctx context.Context, im *academy.Importer) error {
+		for i := 0; i < count; i++ {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			err := im.Add(&models.Object{
+				Class: className,
+				Properties: map[string]interface{}{
+					"title": fmt.Sprintf("Title %v", i),
+					"url":   fmt.Sprintf("https://example.com/article/%v", i),
+				},
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func DummyDelete(ctx context.Context, client *weaviate.Client, className, id string) error {
+	err := client.Data().Deleter().
+		WithClassName(className).
+		WithID(id).
+		Do(ctx)
+	return academy.Classify("delete object "+id, err)
+}
 
-	b, err := json.MarshalIndent(meta, "", "  ")
+func DummyCreate(ctx context.Context, client *weaviate.Client, className string) error {
+	res, err := client.Data().Creator().
+		WithClassName(className).
+		WithProperties(map[string]interface{}{
+			"name": "dummy",
+		}).Do(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return academy.Classify("create object", err)
 	}
-	fmt.Println(string(b))
+
+	return cli.Print(res)
 }
@@ -0,0 +1,123 @@
+// Package cli is the small subcommand dispatcher shared by the programs under cmd/.
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ErrUsage is returned when the arguments do not select a runnable command.
+// The usage text has already been printed when it is returned.
+var ErrUsage = errors.New("invalid usage")
+
+// Command is a node in the command tree. Leaf commands set Run, group
+// commands set Subcommands. A command setting both runs itself unless the
+// first argument names one of its subcommands.
+type Command struct {
+	Name        string
+	Usage       string
+	Summary     string
+	Run         func(ctx context.Context, args []string) error
+	Subcommands []*Command
+}
+
+// Run selects the command named by args[0] from cmds and runs it with the
+// remaining arguments, descending into subcommands as needed.
+func Run(ctx context.Context, prog string, cmds []*Command, args []string) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		PrintUsage(os.Stderr, prog, cmds)
+		if len(args) == 0 {
+			return ErrUsage
+		}
+		return nil
+	}
+
+	for _, cmd := range cmds {
+		if cmd.Name != args[0] {
+			continue
+		}
+		path := prog + " " + cmd.Name
+		if len(cmd.Subcommands) > 0 && (cmd.Run == nil || len(args) > 1 && find(cmd.Subcommands, args[1]) != nil) {
+			return Run(ctx, path, cmd.Subcommands, args[1:])
+		}
+		err := cmd.Run(ctx, args[1:])
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "%s: unknown command %q\n\n", prog, args[0])
+	PrintUsage(os.Stderr, prog, cmds)
+	return ErrUsage
+}
+
+func find(cmds []*Command, name string) *Command {
+	for _, cmd := range cmds {
+		if cmd.Name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// PrintUsage writes the list of available commands to w.
+func PrintUsage(w io.Writer, prog string, cmds []*Command) {
+	fmt.Fprintf(w, "Usage: %s <command> [flags]\n\nCommands:\n", prog)
+	for _, cmd := range cmds {
+		name := cmd.Name
+		if cmd.Usage != "" {
+			name += " " + cmd.Usage
+		}
+		fmt.Fprintf(w, "  %-32s %s\n", name, cmd.Summary)
+	}
+}
+
+// ParseGlobal parses the program wide flags in front of the command name and
+// returns the remaining arguments.
+func ParseGlobal(fs *flag.FlagSet, cmds []*Command, args []string) ([]string, error) {
+	fs.BoolVar(&debug, "debug", false, "print the underlying error and server response on failure")
+	fs.Var(&outputFormat, "output", "result format: json, jsonl, table, csv or yaml")
+	fs.Var(responseMode{}, "graphql-errors", "fail or warn on GraphQL and generate/ask errors in query results")
+	fs.Usage = func() {
+		PrintUsage(fs.Output(), fs.Name(), cmds)
+		fmt.Fprintln(fs.Output(), "\nGlobal flags, given before the command:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, err
+		}
+		return nil, ErrUsage
+	}
+	return fs.Args(), nil
+}
+
+// NewFlagSet returns a flag set for a leaf command that reports parse errors
+// instead of exiting the process.
+func NewFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	return fs
+}
+
+// Parse parses args into fs, rejecting stray positional arguments.
+func Parse(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return ErrUsage
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(fs.Output(), "unexpected arguments: %s\n", strings.Join(fs.Args(), " "))
+		fs.Usage()
+		return ErrUsage
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"example.com/weaviate-tutorial/pkg/academy"
+	"flag"
+	"os"
+	"time"
+)
+
+// BackoffFlags registers --retries and --backoff on fs, filling b.
+func BackoffFlags(fs *flag.FlagSet, b *academy.Backoff) {
+	fs.IntVar(&b.Retries, "retries", 5, "retries of a batch or object failing with a transient error, 0 to never retry")
+	fs.DurationVar(&b.Initial, "backoff", 500*time.Millisecond, "delay before the first retry, doubled for every further one")
+}
+
+// DeadLetterFile is an io.Writer that creates, or truncates, the file at Path
+// on the first Write, so a dead-letter file only appears when an object
+// failed. With Append an existing file is extended instead. Lines counts the
+// lines written.
+type DeadLetterFile struct {
+	Path   string
+	Append bool
+	Lines  int
+	f      *os.File
+}
+
+func (d *DeadLetterFile) Write(p []byte) (int, error) {
+	if d.f == nil {
+		flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		if d.Append {
+			flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+		}
+		f, err := os.OpenFile(d.Path, flags, 0o644)
+		if err != nil {
+			return 0, err
+		}
+		d.f = f
+	}
+	for _, c := range p {
+		if c == '\n' {
+			d.Lines++
+		}
+	}
+	return d.f.Write(p)
+}
+
+// Close closes the file if it was created.
+func (d *DeadLetterFile) Close() error {
+	if d.f == nil {
+		return nil
+	}
+	return d.f.Close()
+}
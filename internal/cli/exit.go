@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"example.com/weaviate-tutorial/internal/output"
+	"example.com/weaviate-tutorial/pkg/academy"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// Exit codes, one per academy.Kind. Scripts may rely on these.
+const (
+	ExitOK            = 0
+	ExitFailure       = 1
+	ExitUsage         = 2
+	ExitUnreachable   = 3
+	ExitUnauthorized  = 4
+	ExitClassNotFound = 5
+	ExitAlreadyExists = 6
+	ExitValidation    = 7
+	ExitGraphQL       = 8
+	ExitPartialBatch  = 9
+	// ExitInterrupted is the shell convention for a process stopped by
+	// Ctrl-C.
+	ExitInterrupted = 130
+)
+
+var exitCodes = map[academy.Kind]int{
+	academy.KindUnreachable:   ExitUnreachable,
+	academy.KindUnauthorized:  ExitUnauthorized,
+	academy.KindClassNotFound: ExitClassNotFound,
+	academy.KindAlreadyExists: ExitAlreadyExists,
+	academy.KindValidation:    ExitValidation,
+	academy.KindGraphQL:       ExitGraphQL,
+	academy.KindPartialBatch:  ExitPartialBatch,
+}
+
+// debug is set by the global --debug flag.
+var debug bool
+
+// warnResponses is set by the global --graphql-errors=warn flag.
+var warnResponses bool
+
+type responseMode struct{}
+
+func (responseMode) String() string {
+	if warnResponses {
+		return "warn"
+	}
+	return "fail"
+}
+
+func (responseMode) Set(v string) error {
+	switch v {
+	case "fail":
+		warnResponses = false
+	case "warn":
+		warnResponses = true
+	default:
+		return fmt.Errorf("must be fail or warn")
+	}
+	return nil
+}
+
+// outputFormat is set by the global --output flag.
+var outputFormat = output.JSON
+
+// Print writes a command result to stdout in the --output format.
+func Print(v any) error {
+	return output.Write(os.Stdout, outputFormat, v)
+}
+
+// Tolerate reports the GraphQL and per object errors carried by err, as
+// returned from the academy search and aggregate functions. By default they
+// fail the command; with --graphql-errors=warn they are printed to stderr
+// and the command carries on with the partial results.
+func Tolerate(err error) error {
+	var e *academy.Error
+	if !warnResponses || !errors.As(err, &e) || len(e.Problems) == 0 {
+		return err
+	}
+	for _, p := range e.Problems {
+		fmt.Fprintf(os.Stderr, "warning: %s: %s\n", e.Op, p)
+	}
+	return nil
+}
+
+// ExitCode maps err to the process exit status.
+func ExitCode(err error) int {
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return ExitOK
+	case errors.Is(err, ErrUsage):
+		return ExitUsage
+	case errors.Is(err, context.Canceled):
+		return ExitInterrupted
+	}
+	if code, ok := exitCodes[academy.KindOf(err)]; ok {
+		return code
+	}
+	return ExitFailure
+}
+
+// Exit terminates the process with the status for err, printing a one line
+// message unless err is a usage error that has already been explained. With
+// --debug the underlying error and server response are printed as well.
+func Exit(prog string, err error) {
+	code := ExitCode(err)
+	if code != ExitOK && code != ExitUsage {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", prog, err)
+		var e *academy.Error
+		if debug && errors.As(err, &e) {
+			fmt.Fprintf(os.Stderr, "kind: %s\n", e.Kind)
+			if e.Err != nil {
+				fmt.Fprintf(os.Stderr, "cause: %#v\n", e.Err)
+			}
+			if e.Detail != "" {
+				fmt.Fprintf(os.Stderr, "response: %s\n", e.Detail)
+			}
+		}
+	}
+	os.Exit(code)
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"flag"
+	"strings"
+)
+
+// StringList is a flag.Value collecting comma separated and/or repeated
+// values. The first explicit use replaces the default.
+type StringList struct {
+	values []string
+	set    bool
+}
+
+// NewStringList returns a StringList holding defaults until the flag is set.
+func NewStringList(defaults ...string) *StringList {
+	return &StringList{values: defaults}
+}
+
+func (l *StringList) String() string {
+	if l == nil {
+		return ""
+	}
+	return strings.Join(l.values, ",")
+}
+
+func (l *StringList) Set(v string) error {
+	if !l.set {
+		l.values = nil
+		l.set = true
+	}
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			l.values = append(l.values, s)
+		}
+	}
+	return nil
+}
+
+// Values returns the collected values.
+func (l *StringList) Values() []string {
+	return l.values
+}
+
+// IsSet reports whether the flag called name was given explicitly.
+func IsSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"example.com/weaviate-tutorial/pkg/academy"
+	"flag"
+	"fmt"
+	"github.com/weaviate/weaviate-go-client/v4/weaviate"
+	"os"
+)
+
+// ImportFlags registers the flags tuning an Importer: --batch-size,
+// --workers, --queue, --retries and --backoff.
+func ImportFlags(fs *flag.FlagSet) *academy.ImportOptions {
+	opts := &academy.ImportOptions{}
+	fs.IntVar(&opts.BatchSize, "batch-size", 100, "objects per batch")
+	fs.IntVar(&opts.Workers, "workers", 4, "batches sent at the same time")
+	fs.IntVar(&opts.QueueSize, "queue", 8, "full batches waiting for a worker before reading pauses")
+	BackoffFlags(fs, &opts.Backoff)
+	return opts
+}
+
+// RunImport feeds an Importer with add and reports the outcome: a line per
+// batch and the rejected objects on stderr, the stats on stdout. Objects that
+// finally fail are written to dl unless its path is empty. On Ctrl-C add's
+// context is cancelled, but the batches already read are sent.
+func RunImport(ctx context.Context, client *weaviate.Client, dl *DeadLetterFile, opts academy.ImportOptions, add func(context.Context, *academy.Importer) error) error {
+	ctx, stop := Interruptible(ctx)
+	defer stop()
+	if dl.Path != "" {
+		opts.DeadLetter = dl
+	}
+	if opts.Progress == nil {
+		opts.Progress = ImportProgress
+	}
+	// The requests outlive Ctrl-C so the batches in flight complete.
+	im := academy.NewImporter(context.WithoutCancel(ctx), client, opts)
+	err := add(ctx, im)
+	// Send what was decoded before a malformed element or Ctrl-C too.
+	stats, cerr := im.Close()
+	switch {
+	case errors.Is(err, context.Canceled):
+		err = fmt.Errorf("import interrupted after %d objects: %w", stats.Added, err)
+	case err == nil:
+		err = cerr
+	}
+	if derr := dl.Close(); derr != nil && err == nil {
+		err = derr
+	}
+	if dl.Lines > 0 {
+		fmt.Fprintf(os.Stderr, "%d failed objects written to %s\n", dl.Lines, dl.Path)
+	}
+	for _, f := range stats.Failed {
+		for _, msg := range f.Messages {
+			fmt.Fprintf(os.Stderr, "object %d (%s): %s\n", f.Index, f.ID, msg)
+		}
+	}
+	if perr := Print(stats); perr != nil {
+		return perr
+	}
+	return err
+}
+
+// ImportProgress prints a line per batch on stderr.
+func ImportProgress(s academy.ImportStats) {
+	fmt.Fprintf(os.Stderr, "batch %d: %d imported, %d failed\n", s.Batches, s.Imported, len(s.Failed))
+}
@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Confirm asks a yes/no question on stderr and reads the answer from stdin.
+// It fails with ErrUsage when stdin is not a terminal, so scripts have to
+// pass an explicit flag such as --yes instead.
+func Confirm(question string) (bool, error) {
+	info, err := os.Stdin.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		fmt.Fprintf(os.Stderr, "%s: stdin is not a terminal, pass --yes to confirm\n", question)
+		return false, ErrUsage
+	}
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false, err
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
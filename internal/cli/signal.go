@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+)
+
+// Interruptible returns a copy of ctx that is cancelled by the first Ctrl-C,
+// so a long running command can stop taking on work and finish what is in
+// flight. A second Ctrl-C terminates the process right away.
+func Interruptible(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	go func() {
+		select {
+		case <-sig:
+			signal.Stop(sig)
+			fmt.Fprintln(os.Stderr, "interrupted, finishing the work in flight; press Ctrl-C again to abort")
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() {
+		signal.Stop(sig)
+		cancel()
+	}
+}
@@ -0,0 +1,263 @@
+// Package config resolves how the commands connect to Weaviate.
+//
+// A connection starts from a named profile, built in or read from the
+// profiles file. Module API keys and timeouts from the environment are
+// layered on top, and command line flags override everything.
+package config
+
+import (
+	"example.com/weaviate-tutorial/pkg/academy"
+	"fmt"
+	"github.com/weaviate/weaviate-go-client/v4/weaviate"
+	"github.com/weaviate/weaviate-go-client/v4/weaviate/auth"
+	"github.com/weaviate/weaviate-go-client/v4/weaviate/connection"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Profile is one named connection in the profiles file.
+type Profile struct {
+	Host           string            `yaml:"host"`
+	Scheme         string            `yaml:"scheme"`
+	APIKey         string            `yaml:"apiKey"`
+	OIDC           OIDC              `yaml:"oidc"`
+	StartupTimeout time.Duration     `yaml:"startupTimeout"`
+	Timeout        time.Duration     `yaml:"timeout"`
+	Headers        map[string]string `yaml:"headers"`
+	Aliases        string            `yaml:"aliases"`
+}
+
+// OIDC holds the credentials for the resource owner password or the client
+// credentials flow. Username takes precedence over ClientSecret.
+type OIDC struct {
+	Username     string   `yaml:"username"`
+	Password     string   `yaml:"password"`
+	ClientSecret string   `yaml:"clientSecret"`
+	Scopes       []string `yaml:"scopes"`
+}
+
+// moduleHeaders maps the environment variables holding third party API keys
+// to the request headers the Weaviate modules expect.
+var moduleHeaders = map[string]string{
+	"OPENAI_APIKEY":      "X-OpenAI-Api-Key",
+	"OPENAI_ORG":         "X-OpenAI-Organization",
+	"COHERE_APIKEY":      "X-Cohere-Api-Key",
+	"HUGGINGFACE_APIKEY": "X-HuggingFace-Api-Key",
+}
+
+// merge overlays the non-zero fields of o onto p.
+func (p *Profile) merge(o Profile) {
+	if o.Host != "" {
+		p.Host = o.Host
+	}
+	if o.Scheme != "" {
+		p.Scheme = o.Scheme
+	}
+	if o.APIKey != "" {
+		p.APIKey = o.APIKey
+	}
+	if o.OIDC.Username != "" {
+		p.OIDC.Username = o.OIDC.Username
+	}
+	if o.OIDC.Password != "" {
+		p.OIDC.Password = o.OIDC.Password
+	}
+	if o.OIDC.ClientSecret != "" {
+		p.OIDC.ClientSecret = o.OIDC.ClientSecret
+	}
+	if len(o.OIDC.Scopes) > 0 {
+		p.OIDC.Scopes = o.OIDC.Scopes
+	}
+	if o.StartupTimeout != 0 {
+		p.StartupTimeout = o.StartupTimeout
+	}
+	if o.Timeout != 0 {
+		p.Timeout = o.Timeout
+	}
+	if o.Aliases != "" {
+		p.Aliases = o.Aliases
+	}
+	for k, v := range o.Headers {
+		if p.Headers == nil {
+			p.Headers = make(map[string]string)
+		}
+		p.Headers[k] = v
+	}
+}
+
+// envProfile builds the "env" profile from WEAVIATE_HOST, WEAVIATE_SCHEME,
+// WEAVIATE_API_KEY and the WEAVIATE_OIDC_* variables, defaulting to the
+// docker-compose instance.
+func envProfile() Profile {
+	p := Profile{
+		Host:   os.Getenv("WEAVIATE_HOST"),
+		Scheme: os.Getenv("WEAVIATE_SCHEME"),
+		APIKey: os.Getenv("WEAVIATE_API_KEY"),
+		OIDC: OIDC{
+			Username:     os.Getenv("WEAVIATE_OIDC_USERNAME"),
+			Password:     os.Getenv("WEAVIATE_OIDC_PASSWORD"),
+			ClientSecret: os.Getenv("WEAVIATE_OIDC_CLIENT_SECRET"),
+		},
+	}
+	if p.Host == "" {
+		p.Host = "localhost:8080"
+	}
+	if p.Scheme == "" {
+		p.Scheme = "http"
+	}
+	return p
+}
+
+// envOverrides reads the module API keys, timeouts and alias store that
+// apply to every profile.
+func envOverrides() (Profile, error) {
+	p := Profile{Aliases: os.Getenv("WEAVIATE_ALIASES")}
+	for env, header := range moduleHeaders {
+		if v := os.Getenv(env); v != "" {
+			if p.Headers == nil {
+				p.Headers = make(map[string]string)
+			}
+			p.Headers[header] = v
+		}
+	}
+	var err error
+	if p.StartupTimeout, err = durationEnv("WEAVIATE_STARTUP_TIMEOUT"); err != nil {
+		return p, err
+	}
+	if p.Timeout, err = durationEnv("WEAVIATE_TIMEOUT"); err != nil {
+		return p, err
+	}
+	return p, nil
+}
+
+func durationEnv(name string) (time.Duration, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", name, err)
+	}
+	return d, nil
+}
+
+// Settings is a fully resolved connection.
+type Settings struct {
+	Name string
+	Profile
+}
+
+func (s Settings) authConfig() auth.Config {
+	switch {
+	case s.OIDC.Username != "":
+		return auth.ResourceOwnerPasswordFlow{Username: s.OIDC.Username, Password: s.OIDC.Password, Scopes: s.OIDC.Scopes}
+	case s.OIDC.ClientSecret != "":
+		return auth.ClientCredentials{ClientSecret: s.OIDC.ClientSecret, Scopes: s.OIDC.Scopes}
+	case s.APIKey != "":
+		return auth.ApiKey{Value: s.APIKey}
+	}
+	return nil
+}
+
+// Config builds the client configuration. Authentication is resolved here,
+// rather than by weaviate.NewClient, so that the request timeout also applies
+// to the OIDC token refreshing HTTP client.
+func (s Settings) Config() (weaviate.Config, error) {
+	if s.Host == "" {
+		return weaviate.Config{}, academy.Errorf(academy.KindValidation, "config",
+			"no Weaviate host configured for profile %q, use --host or WEAVIATE_HOST", s.Name)
+	}
+	headers := make(map[string]string, len(s.Headers))
+	for k, v := range s.Headers {
+		if v != "" {
+			headers[k] = v
+		}
+	}
+
+	httpClient := &http.Client{}
+	if authConfig := s.authConfig(); authConfig != nil {
+		tmpCon := connection.NewConnection(s.Scheme, s.Host, nil, headers)
+		if err := tmpCon.WaitForWeaviate(s.StartupTimeout); err != nil {
+			return weaviate.Config{}, academy.Classify("connect", err)
+		}
+		authClient, authHeaders, err := authConfig.GetAuthInfo(tmpCon)
+		if err != nil {
+			return weaviate.Config{}, academy.Classify("authenticate", err)
+		}
+		if authClient != nil {
+			httpClient = authClient
+		}
+		for k, v := range authHeaders {
+			headers[k] = v
+		}
+	}
+	httpClient.Timeout = s.Timeout
+
+	return weaviate.Config{
+		Host:             s.Host,
+		Scheme:           s.Scheme,
+		ConnectionClient: httpClient,
+		Headers:          headers,
+		StartupTimeout:   s.StartupTimeout,
+	}, nil
+}
+
+// NewClient builds a client from the resolved settings.
+func (s Settings) NewClient() (*weaviate.Client, error) {
+	cfg, err := s.Config()
+	if err != nil {
+		return nil, err
+	}
+	return weaviate.NewClient(cfg)
+}
+
+// DefaultAliasesFile is the alias store of profiles that do not set one.
+const DefaultAliasesFile = "weaviate-aliases.json"
+
+// AliasStore returns where the class aliases live: "class" or
+// "class:<Name>" for a metadata class on the server, anything else is a
+// local JSON file.
+func (s Settings) AliasStore(client *weaviate.Client) academy.AliasStore {
+	switch {
+	case s.Aliases == "class":
+		return academy.ClassAliasStore{Client: client}
+	case strings.HasPrefix(s.Aliases, "class:"):
+		return academy.ClassAliasStore{Client: client, ClassName: strings.TrimPrefix(s.Aliases, "class:")}
+	case s.Aliases == "":
+		return academy.FileAliasStore{Path: DefaultAliasesFile}
+	}
+	return academy.FileAliasStore{Path: s.Aliases}
+}
+
+// URL returns the server base URL.
+func (s Settings) URL() string {
+	return s.Scheme + "://" + s.Host
+}
+
+// String describes the connection without leaking credentials.
+func (s Settings) String() string {
+	var authKind string
+	switch s.authConfig().(type) {
+	case auth.ResourceOwnerPasswordFlow:
+		authKind = "oidc password"
+	case auth.ClientCredentials:
+		authKind = "oidc client credentials"
+	case auth.ApiKey:
+		authKind = "api key"
+	default:
+		authKind = "anonymous"
+	}
+	var headers []string
+	for k, v := range s.Headers {
+		if v != "" {
+			headers = append(headers, k)
+		}
+	}
+	sort.Strings(headers)
+	return fmt.Sprintf("profile=%s url=%s auth=%s headers=[%s]",
+		s.Name, s.URL(), authKind, strings.Join(headers, ","))
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Flags holds the connection flags of a command.
+type Flags struct {
+	profile      string
+	profilesFile string
+	overrides    Profile
+}
+
+// RegisterFlags registers the connection flags on fs.
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	f := &Flags{}
+	fs.StringVar(&f.profile, "profile", "", "connection profile (env WEAVIATE_PROFILE)")
+	fs.StringVar(&f.profilesFile, "profiles", "", "profiles file (env WEAVIATE_PROFILES, default ./"+DefaultProfilesFile+")")
+	fs.StringVar(&f.overrides.Host, "host", "", "Weaviate host and port")
+	fs.StringVar(&f.overrides.Scheme, "scheme", "", "http or https")
+	fs.StringVar(&f.overrides.APIKey, "api-key", "", "Weaviate API key")
+	fs.StringVar(&f.overrides.OIDC.Username, "oidc-username", "", "OIDC resource owner username")
+	fs.StringVar(&f.overrides.OIDC.Password, "oidc-password", "", "OIDC resource owner password")
+	fs.StringVar(&f.overrides.OIDC.ClientSecret, "oidc-client-secret", "", "OIDC client credentials secret")
+	fs.DurationVar(&f.overrides.StartupTimeout, "startup-timeout", 0, "wait this long for Weaviate to become ready (env WEAVIATE_STARTUP_TIMEOUT)")
+	fs.DurationVar(&f.overrides.Timeout, "timeout", 0, "per request timeout (env WEAVIATE_TIMEOUT)")
+	fs.Var((*headerFlag)(&f.overrides.Headers), "header", "extra request header as Name=value, repeatable")
+	fs.StringVar(&f.overrides.Aliases, "aliases", "", "class alias store: a JSON file or class[:Name] (env WEAVIATE_ALIASES, default ./"+DefaultAliasesFile+")")
+	return f
+}
+
+// Resolve selects the profile named by --profile, WEAVIATE_PROFILE or
+// defaultProfile, in that order, and applies environment and flag overrides.
+func (f *Flags) Resolve(defaultProfile string) (Settings, error) {
+	path := f.profilesFile
+	if path == "" {
+		path = os.Getenv("WEAVIATE_PROFILES")
+	}
+	profiles, err := LoadProfiles(path)
+	if err != nil {
+		return Settings{}, err
+	}
+
+	name := f.profile
+	if name == "" {
+		name = os.Getenv("WEAVIATE_PROFILE")
+	}
+	if name == "" {
+		name = defaultProfile
+	}
+	profile, err := lookup(profiles, name)
+	if err != nil {
+		return Settings{}, err
+	}
+
+	env, err := envOverrides()
+	if err != nil {
+		return Settings{}, err
+	}
+	profile.merge(env)
+	profile.merge(f.overrides)
+	return Settings{Name: name, Profile: profile}, nil
+}
+
+type headerFlag map[string]string
+
+func (h *headerFlag) String() string {
+	if h == nil {
+		return ""
+	}
+	var pairs []string
+	for k := range *h {
+		pairs = append(pairs, k+"=...")
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (h *headerFlag) Set(v string) error {
+	name, value, ok := strings.Cut(v, "=")
+	if !ok || name == "" {
+		return fmt.Errorf("header %q is not Name=value", v)
+	}
+	if *h == nil {
+		*h = make(headerFlag)
+	}
+	(*h)[name] = value
+	return nil
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"errors"
+	"example.com/weaviate-tutorial/pkg/academy"
+	"gopkg.in/yaml.v3"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultProfilesFile is looked up in the working directory and then in the
+// user config directory when no profiles file is given explicitly.
+const DefaultProfilesFile = "weaviate-profiles.yaml"
+
+func builtinProfiles() map[string]Profile {
+	return map[string]Profile{
+		"env": envProfile(),
+		"local-docker": {
+			Host:   "localhost:8080",
+			Scheme: "http",
+		},
+		"edu-demo": {
+			Host:   "edu-demo.weaviate.network",
+			Scheme: "https",
+			APIKey: "readonly-demo",
+		},
+	}
+}
+
+type profilesFile struct {
+	Profiles map[string]Profile `yaml:"profiles"`
+}
+
+// defaultProfilesPaths lists the candidate locations of the profiles file.
+func defaultProfilesPaths() []string {
+	paths := []string{DefaultProfilesFile}
+	if dir, err := os.UserConfigDir(); err == nil {
+		paths = append(paths, filepath.Join(dir, "weaviate-academy", "profiles.yaml"))
+	}
+	return paths
+}
+
+// LoadProfiles returns the built-in profiles overlaid with those from path.
+// An empty path searches the default locations and tolerates a missing file.
+// ${VAR} references in the file are expanded from the environment.
+func LoadProfiles(path string) (map[string]Profile, error) {
+	profiles := builtinProfiles()
+
+	paths := []string{path}
+	if path == "" {
+		paths = defaultProfilesPaths()
+	}
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if errors.Is(err, fs.ErrNotExist) && path == "" {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var f profilesFile
+		if err := yaml.Unmarshal([]byte(os.ExpandEnv(string(data))), &f); err != nil {
+			return nil, academy.Errorf(academy.KindValidation, "config", "%s: %v", p, err)
+		}
+		for name, profile := range f.Profiles {
+			base := profiles[name]
+			base.merge(profile)
+			profiles[name] = base
+		}
+		break
+	}
+	return profiles, nil
+}
+
+// Names returns the sorted profile names.
+func Names(profiles map[string]Profile) []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func lookup(profiles map[string]Profile, name string) (Profile, error) {
+	p, ok := profiles[name]
+	if !ok {
+		return Profile{}, academy.Errorf(academy.KindValidation, "config",
+			"unknown profile %q, known profiles: %s", name, strings.Join(Names(profiles), ", "))
+	}
+	return p, nil
+}
@@ -0,0 +1,377 @@
+// Package output renders command results as JSON, JSON lines, YAML, CSV or
+// aligned text tables.
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/weaviate/weaviate/entities/models"
+	"gopkg.in/yaml.v3"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Format selects how results are rendered.
+type Format string
+
+const (
+	JSON  Format = "json"
+	JSONL Format = "jsonl"
+	Table Format = "table"
+	CSV   Format = "csv"
+	YAML  Format = "yaml"
+)
+
+// Formats lists the supported formats.
+var Formats = []Format{JSON, JSONL, Table, CSV, YAML}
+
+func (f *Format) String() string {
+	return string(*f)
+}
+
+func (f *Format) Set(v string) error {
+	for _, known := range Formats {
+		if Format(v) == known {
+			*f = known
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown format %q", v)
+}
+
+// maxCellWidth bounds table cells so long texts such as wiki summaries do
+// not wreck the layout. CSV cells are never truncated or abbreviated.
+const maxCellWidth = 60
+
+// Write renders v to w. GraphQL responses are split into one record per
+// returned object or aggregate group; other values are rendered as a single
+// record, or one per element if they are lists.
+func Write(w io.Writer, format Format, v any) error {
+	switch format {
+	case JSON, "":
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	case YAML:
+		generic, err := toGeneric(v)
+		if err != nil {
+			return err
+		}
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(generic); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+
+	groups, err := groups(v)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case JSONL:
+		enc := json.NewEncoder(w)
+		for _, g := range groups {
+			for _, r := range g.records {
+				if err := enc.Encode(r); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	case Table:
+		for i, g := range groups {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			if g.title != "" {
+				fmt.Fprintf(w, "%s\n", g.title)
+			}
+			if err := writeTable(w, g.table()); err != nil {
+				return err
+			}
+		}
+		return nil
+	case CSV:
+		t := csvTable(groups)
+		cw := csv.NewWriter(w)
+		if err := cw.Write(t.columns); err != nil {
+			return err
+		}
+		if err := cw.WriteAll(t.rows); err != nil {
+			return err
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+// group is a titled list of records, e.g. the objects of one class.
+type group struct {
+	title   string
+	records []any
+}
+
+func groups(v any) ([]group, error) {
+	if res, ok := v.(*models.GraphQLResponse); ok {
+		return responseGroups(res), nil
+	}
+	generic, err := toGeneric(v)
+	if err != nil {
+		return nil, err
+	}
+	if list, ok := generic.([]any); ok {
+		return []group{{records: list}}, nil
+	}
+	return []group{{records: []any{generic}}}, nil
+}
+
+// responseGroups returns one group per Get, Aggregate or Explore class.
+func responseGroups(res *models.GraphQLResponse) []group {
+	var groups []group
+	for _, op := range sortedKeys(res.Data) {
+		classes, ok := res.Data[op].(map[string]any)
+		if !ok {
+			continue
+		}
+		for _, class := range sortedKeys(classes) {
+			records, _ := classes[class].([]any)
+			groups = append(groups, group{title: op + " " + class, records: records})
+		}
+	}
+	return groups
+}
+
+type table struct {
+	columns []string
+	rows    [][]string
+}
+
+// table flattens the records. Nested objects become dotted columns, with
+// _additional.x shortened to _x. The first list of objects of a record, such
+// as topOccurrences, expands into one row per element; any further list is
+// kept as a JSON cell, so the rows never multiply.
+func (g group) table() table {
+	var flat []map[string]string
+	for _, r := range g.records {
+		expand := true
+		flat = append(flat, flatten("", r, &expand)...)
+	}
+	return tableOf(flat)
+}
+
+// csvTable puts the records of every group under a single header, with a
+// _source column naming the group if there are several.
+func csvTable(groups []group) table {
+	var flat []map[string]string
+	for _, g := range groups {
+		for _, r := range g.records {
+			expand := true
+			rows := flatten("", r, &expand)
+			if len(groups) > 1 {
+				for _, row := range rows {
+					row["_source"] = g.title
+				}
+			}
+			flat = append(flat, rows...)
+		}
+	}
+	t := tableOf(flat)
+	if len(groups) > 1 {
+		// _source first, it says what the other columns mean.
+		for i, col := range t.columns {
+			if col != "_source" {
+				continue
+			}
+			copy(t.columns[1:i+1], t.columns[:i])
+			t.columns[0] = col
+			for _, row := range t.rows {
+				cell := row[i]
+				copy(row[1:i+1], row[:i])
+				row[0] = cell
+			}
+			break
+		}
+	}
+	return t
+}
+
+// tableOf lays rows out under the union of their columns, properties first
+// and _additional ones last.
+func tableOf(flat []map[string]string) table {
+	seen := make(map[string]bool)
+	var columns []string
+	for _, row := range flat {
+		for col := range row {
+			if !seen[col] {
+				seen[col] = true
+				columns = append(columns, col)
+			}
+		}
+	}
+	sort.Slice(columns, func(i, j int) bool {
+		ai, aj := strings.HasPrefix(columns[i], "_"), strings.HasPrefix(columns[j], "_")
+		if ai != aj {
+			return aj
+		}
+		return columns[i] < columns[j]
+	})
+
+	t := table{columns: columns}
+	for _, row := range flat {
+		cells := make([]string, len(columns))
+		for i, col := range columns {
+			cells[i] = row[col]
+		}
+		t.rows = append(t.rows, cells)
+	}
+	return t
+}
+
+// flatten turns v into one or more rows keyed by column path. Only one list
+// of objects expands into rows, while *expand is set; it is cleared then.
+func flatten(prefix string, v any, expand *bool) []map[string]string {
+	rows := []map[string]string{{}}
+	switch val := v.(type) {
+	case map[string]any:
+		for _, k := range sortedKeys(val) {
+			if val[k] == nil && k == "_additional" {
+				continue
+			}
+			col := k
+			switch {
+			case prefix == "" && k == "_additional":
+				col = "_"
+			case prefix == "_":
+				col = "_" + k
+			case prefix != "":
+				col = prefix + "." + k
+			}
+			sub := flatten(col, val[k], expand)
+			rows = product(rows, sub)
+		}
+	case []any:
+		if !objects(val) || !*expand {
+			rows[0][prefix] = cell(val)
+			break
+		}
+		*expand = false
+		var expanded []map[string]string
+		for _, elem := range val {
+			expanded = append(expanded, flatten(prefix, elem, expand)...)
+		}
+		if len(expanded) > 0 {
+			rows = expanded
+		}
+	default:
+		rows[0][prefix] = cell(val)
+	}
+	return rows
+}
+
+// product combines every row of a with every row of b. As only one list
+// expands, one of them has a single row.
+func product(a, b []map[string]string) []map[string]string {
+	out := make([]map[string]string, 0, len(a)*len(b))
+	for _, ra := range a {
+		for _, rb := range b {
+			row := make(map[string]string, len(ra)+len(rb))
+			for k, v := range ra {
+				row[k] = v
+			}
+			for k, v := range rb {
+				row[k] = v
+			}
+			out = append(out, row)
+		}
+	}
+	return out
+}
+
+func objects(list []any) bool {
+	for _, elem := range list {
+		if _, ok := elem.(map[string]any); !ok {
+			return false
+		}
+	}
+	return len(list) > 0
+}
+
+func cell(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+func writeTable(w io.Writer, t table) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(t.columns, "\t"))
+	for _, row := range t.rows {
+		cells := make([]string, len(row))
+		for i, c := range row {
+			c = abbreviate(c)
+			c = strings.Join(strings.Fields(c), " ")
+			if r := []rune(c); len(r) > maxCellWidth {
+				c = string(r[:maxCellWidth-1]) + "…"
+			}
+			cells[i] = c
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+// maxListValues bounds the lists shown in table cells; longer ones, such as
+// vectors, are abbreviated to their length.
+const maxListValues = 8
+
+// abbreviate replaces a cell holding a JSON list of more than maxListValues
+// values with their count.
+func abbreviate(c string) string {
+	if !strings.HasPrefix(c, "[") {
+		return c
+	}
+	var list []any
+	if err := json.Unmarshal([]byte(c), &list); err != nil || len(list) <= maxListValues {
+		return c
+	}
+	return fmt.Sprintf("[%d values]", len(list))
+}
+
+// toGeneric converts v to maps, lists and scalars following its JSON tags.
+func toGeneric(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic any
+	if err := json.Unmarshal(b, &generic); err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,170 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/weaviate/weaviate/entities/models"
+	"strings"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		body   string
+		want   string
+	}{
+		{
+			name:   "get with additional",
+			format: CSV,
+			body: `{"data": {"Get": {"JeopardyQuestion": [
+				{"question": "q1", "answer": "a1", "_additional": {"id": "1", "distance": 0.25}},
+				{"question": "q2", "answer": "a2", "_additional": {"id": "2", "distance": 0.5}}
+			]}}}`,
+			want: `
+answer,question,_distance,_id
+a1,q1,0.25,1
+a2,q2,0.5,2
+`,
+		},
+		{
+			name:   "get without additional",
+			format: CSV,
+			body:   `{"data": {"Get": {"JeopardyQuestion": [{"question": "q1", "_additional": null}]}}}`,
+			want: `
+question
+q1
+`,
+		},
+		{
+			name:   "get table",
+			format: Table,
+			body: `{"data": {"Get": {"JeopardyQuestion": [
+				{"question": "q1", "answer": "a1", "_additional": {"id": "1"}}
+			]}}}`,
+			want: `
+Get JeopardyQuestion
+answer  question  _id
+a1      q1        1
+`,
+		},
+		{
+			name:   "aggregate groupedBy",
+			format: CSV,
+			body: `{"data": {"Aggregate": {"JeopardyQuestion": [
+				{"groupedBy": {"path": ["round"], "value": "Jeopardy!"}, "meta": {"count": 3}},
+				{"groupedBy": {"path": ["round"], "value": "Double Jeopardy!"}, "meta": {"count": 2}}
+			]}}}`,
+			want: `
+groupedBy.path,groupedBy.value,meta.count
+"[""round""]",Jeopardy!,3
+"[""round""]",Double Jeopardy!,2
+`,
+		},
+		{
+			name:   "aggregate topOccurrences",
+			format: CSV,
+			body: `{"data": {"Aggregate": {"JeopardyQuestion": [
+				{"meta": {"count": 5}, "round": {"topOccurrences": [
+					{"value": "Jeopardy!", "occurs": 3},
+					{"value": "Double Jeopardy!", "occurs": 2}
+				]}}
+			]}}}`,
+			want: `
+meta.count,round.topOccurrences.occurs,round.topOccurrences.value
+5,3,Jeopardy!
+5,2,Double Jeopardy!
+`,
+		},
+		{
+			name:   "only the first list expands",
+			format: CSV,
+			body: `{"data": {"Aggregate": {"JeopardyQuestion": [
+				{"answer": {"topOccurrences": [{"value": "a", "occurs": 1}]},
+				 "round": {"topOccurrences": [{"value": "r1", "occurs": 1}, {"value": "r2", "occurs": 1}]}}
+			]}}}`,
+			want: `
+answer.topOccurrences.occurs,answer.topOccurrences.value,round.topOccurrences
+1,a,"[{""occurs"":1,""value"":""r1""},{""occurs"":1,""value"":""r2""}]"
+`,
+		},
+		{
+			name:   "source of several groups",
+			format: CSV,
+			body: `{"data": {"Get": {
+				"Article": [{"title": "t1"}],
+				"JeopardyQuestion": [{"question": "q1"}, {"question": "q2"}]
+			}}}`,
+			want: `
+_source,question,title
+Get Article,,t1
+Get JeopardyQuestion,q1,
+Get JeopardyQuestion,q2,
+`,
+		},
+		{
+			name:   "long list in csv",
+			format: CSV,
+			body:   `{"data": {"Get": {"Article": [{"title": "t1", "_additional": {"vector": [1, 2, 3, 4, 5, 6, 7, 8, 9, 10]}}]}}}`,
+			want: `
+title,_vector
+t1,"[1,2,3,4,5,6,7,8,9,10]"
+`,
+		},
+		{
+			name:   "long list in table",
+			format: Table,
+			body:   `{"data": {"Get": {"Article": [{"title": "t1", "_additional": {"vector": [1, 2, 3, 4, 5, 6, 7, 8, 9, 10]}}]}}}`,
+			want: `
+Get Article
+title  _vector
+t1     [10 values]
+`,
+		},
+		{
+			name:   "short list in table",
+			format: Table,
+			body:   `{"data": {"Get": {"Article": [{"title": "t1", "tags": ["a", "b"]}]}}}`,
+			want: `
+Get Article
+tags       title
+["a","b"]  t1
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var res models.GraphQLResponse
+			if err := json.Unmarshal([]byte(tt.body), &res); err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err := Write(&buf, tt.format, &res); err != nil {
+				t.Fatal(err)
+			}
+			if got, want := buf.String(), strings.TrimPrefix(tt.want, "\n"); got != want {
+				t.Errorf("got\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestAbbreviate(t *testing.T) {
+	tests := []struct {
+		cell string
+		want string
+	}{
+		{cell: "", want: ""},
+		{cell: "text", want: "text"},
+		{cell: "[1,2,3,4,5,6,7,8]", want: "[1,2,3,4,5,6,7,8]"},
+		{cell: "[1,2,3,4,5,6,7,8,9]", want: "[9 values]"},
+		{cell: "[not json, 1, 2, 3, 4, 5, 6, 7, 8, 9]", want: "[not json, 1, 2, 3, 4, 5, 6, 7, 8, 9]"},
+		{cell: `{"a": 1}`, want: `{"a": 1}`},
+	}
+	for _, tt := range tests {
+		if got := abbreviate(tt.cell); got != tt.want {
+			t.Errorf("abbreviate(%q) = %q, want %q", tt.cell, got, tt.want)
+		}
+	}
+}
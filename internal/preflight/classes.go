@@ -0,0 +1,123 @@
+package preflight
+
+import (
+	"context"
+	"example.com/weaviate-tutorial/pkg/academy"
+	"github.com/weaviate/weaviate/entities/models"
+	"sort"
+	"strings"
+)
+
+// vectorizerPrefixes are the module families that can vectorize a class.
+var vectorizerPrefixes = []string{"text2vec-", "multi2vec-", "img2vec-", "ref2vec-"}
+
+func isVectorizer(module string) bool {
+	for _, p := range vectorizerPrefixes {
+		if strings.HasPrefix(module, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClassRequirements returns the modules and features needed to create
+// classes: the vectorizer, every module configured on the class or one of its
+// properties, BM25 parameters, null state and property length indexes,
+// tokenization options and product quantization. It fails if a
+// property configures a vectorizer other than the class's, since the server
+// would silently ignore those settings.
+func ClassRequirements(classes ...*models.Class) (Requirements, error) {
+	modules := map[string]bool{}
+	features := map[Feature]bool{}
+	for _, c := range classes {
+		if c.Vectorizer != "" && c.Vectorizer != "none" {
+			modules[c.Vectorizer] = true
+		}
+		for name := range moduleConfig(c.ModuleConfig) {
+			modules[name] = true
+		}
+		if pqEnabled(c.VectorIndexConfig) {
+			features[FeaturePQ] = true
+		}
+		if inv := c.InvertedIndexConfig; inv != nil {
+			if inv.Bm25 != nil {
+				features[FeatureBM25] = true
+			}
+			if inv.IndexNullState || inv.IndexPropertyLength {
+				features[FeaturePropertyIndexes] = true
+			}
+		}
+		for _, p := range c.Properties {
+			for name := range moduleConfig(p.ModuleConfig) {
+				if isVectorizer(name) && name != c.Vectorizer {
+					return Requirements{}, academy.Errorf(academy.KindValidation, "validate class "+c.Class,
+						"property %s configures %s but the class is vectorized by %q", p.Name, name, c.Vectorizer)
+				}
+				modules[name] = true
+			}
+			if p.Tokenization != "" && p.Tokenization != models.PropertyTokenizationWord {
+				features[FeatureTokenizationV2] = true
+			}
+		}
+	}
+
+	var reqs Requirements
+	for name := range modules {
+		reqs.Modules = append(reqs.Modules, name)
+	}
+	sort.Strings(reqs.Modules)
+	for f := range features {
+		reqs.Features = append(reqs.Features, f)
+	}
+	sort.Slice(reqs.Features, func(i, j int) bool { return reqs.Features[i] < reqs.Features[j] })
+	return reqs, nil
+}
+
+func moduleConfig(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
+
+func pqEnabled(vectorIndexConfig interface{}) bool {
+	pq := moduleConfig(moduleConfig(vectorIndexConfig)["pq"])
+	enabled, _ := pq["enabled"].(bool)
+	return enabled
+}
+
+// keyHeaders maps a module family to the request header carrying its third
+// party API key.
+var keyHeaders = map[string]string{
+	"openai":      "X-OpenAI-Api-Key",
+	"cohere":      "X-Cohere-Api-Key",
+	"huggingface": "X-HuggingFace-Api-Key",
+	"palm":        "X-Palm-Api-Key",
+}
+
+// keyHeader returns the API key header module needs, if any.
+func keyHeader(module string) (string, bool) {
+	_, family, ok := strings.Cut(module, "-")
+	if !ok {
+		return "", false
+	}
+	header, ok := keyHeaders[family]
+	return header, ok
+}
+
+func hasHeader(headers map[string]string, name string) bool {
+	for k, v := range headers {
+		if strings.EqualFold(k, name) && v != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireClasses validates classes and checks the server provides what they
+// need, see ClassRequirements.
+func (c *Checker) RequireClasses(ctx context.Context, classes ...*models.Class) error {
+	reqs, err := ClassRequirements(classes...)
+	if err != nil {
+		return err
+	}
+	return c.Require(ctx, reqs)
+}
@@ -0,0 +1,119 @@
+package preflight
+
+import (
+	"example.com/weaviate-tutorial/pkg/academy"
+	"reflect"
+	"testing"
+)
+
+func TestClassRequirements(t *testing.T) {
+	tests := []struct {
+		name    string
+		classes string
+		want    Requirements
+		err     bool
+	}{
+		{
+			name:    "no vectorizer",
+			classes: `{class: Plain, vectorizer: none, properties: [{name: title, dataType: [text]}]}`,
+		},
+		{
+			name: "vectorizer and modules",
+			classes: `
+class: Question
+vectorizer: text2vec-openai
+moduleConfig:
+  text2vec-openai: {model: ada}
+  generative-openai: {}
+  qna-openai: {}
+`,
+			want: Requirements{Modules: []string{"generative-openai", "qna-openai", "text2vec-openai"}},
+		},
+		{
+			name: "property of the class vectorizer",
+			classes: `
+class: JeopardyQuestion
+vectorizer: text2vec-contextionary
+properties:
+  - name: round
+    dataType: [text]
+    moduleConfig:
+      text2vec-contextionary: {skip: true}
+`,
+			want: Requirements{Modules: []string{"text2vec-contextionary"}},
+		},
+		{
+			name: "property of another vectorizer",
+			classes: `
+class: JeopardyQuestion
+vectorizer: text2vec-contextionary
+properties:
+  - name: round
+    dataType: [text]
+    moduleConfig:
+      text2vec-openai: {skip: true}
+`,
+			err: true,
+		},
+		{
+			name: "tokenization and pq",
+			classes: `
+class: Article
+vectorizer: none
+vectorIndexConfig: {pq: {enabled: true}}
+properties:
+  - {name: title, dataType: [text], tokenization: word}
+  - {name: url, dataType: [text], tokenization: field}
+`,
+			want: Requirements{Features: []Feature{FeaturePQ, FeatureTokenizationV2}},
+		},
+		{
+			name:    "pq disabled",
+			classes: `{class: Article, vectorizer: none, vectorIndexConfig: {pq: {enabled: false}}}`,
+		},
+		{
+			name: "bm25 and property indexes",
+			classes: `
+class: Article
+vectorizer: none
+invertedIndexConfig:
+  bm25: {b: 0.75, k1: 1.2}
+  indexNullState: true
+`,
+			want: Requirements{Features: []Feature{FeatureBM25, FeaturePropertyIndexes}},
+		},
+		{
+			name:    "stopwords only",
+			classes: `{class: Article, vectorizer: none, invertedIndexConfig: {stopwords: {preset: en}}}`,
+		},
+		{
+			name: "several classes",
+			classes: `
+- {class: A, vectorizer: text2vec-contextionary}
+- {class: B, vectorizer: text2vec-contextionary, moduleConfig: {generative-openai: {}}}
+`,
+			want: Requirements{Modules: []string{"generative-openai", "text2vec-contextionary"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			classes, err := academy.ParseClasses([]byte(tt.classes))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := ClassRequirements(classes...)
+			if tt.err {
+				if academy.KindOf(err) != academy.KindValidation {
+					t.Errorf("err = %v, want a validation error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+// Package preflight checks that the Weaviate server is ready and able to
+// serve a command before the command sends its first real request.
+package preflight
+
+import (
+	"context"
+	"example.com/weaviate-tutorial/pkg/academy"
+	"flag"
+	"fmt"
+	"github.com/weaviate/weaviate-go-client/v4/weaviate"
+	"github.com/weaviate/weaviate/entities/models"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Requirements lists what a command needs from the server. A module name
+// ending in "*" matches any enabled module with that prefix.
+type Requirements struct {
+	Modules  []string
+	Features []Feature
+}
+
+// Options control the preflight, usually from the global flags. Headers are
+// the request headers the client sends, used to check that modules needing a
+// third party API key get one, unless ServerKeys says the server holds the
+// keys itself.
+type Options struct {
+	Wait       time.Duration
+	Skip       bool
+	ServerKeys bool
+	Headers    map[string]string
+}
+
+// RegisterFlags registers the preflight flags on fs.
+func RegisterFlags(fs *flag.FlagSet) *Options {
+	o := &Options{}
+	fs.DurationVar(&o.Wait, "wait", 30*time.Second, "how long to wait for Weaviate to become ready")
+	fs.BoolVar(&o.Skip, "skip-preflight", false, "do not check readiness, version and modules before running")
+	fs.BoolVar(&o.ServerKeys, "server-side-keys", false, "the server holds the third party API keys, e.g. OPENAI_APIKEY in docker-compose.yml, so no key header is needed")
+	return o
+}
+
+// Checker runs the preflight at most once per process and caches the server
+// metadata for later requirement checks.
+type Checker struct {
+	client  *weaviate.Client
+	target  string
+	options Options
+	meta    *models.Meta
+	version Version
+}
+
+// New returns a checker for client. target names the server in messages.
+func New(client *weaviate.Client, target string, options Options) *Checker {
+	return &Checker{client: client, target: target, options: options}
+}
+
+// Require checks the API key headers of the required modules, waits for the
+// server to be ready, then verifies the server version and the required
+// modules and features.
+func (c *Checker) Require(ctx context.Context, reqs Requirements) error {
+	if c.options.Skip {
+		return nil
+	}
+	// Local, so it fails before the first request.
+	if err := c.requireKeys(reqs.Modules); err != nil {
+		return err
+	}
+	if c.meta == nil {
+		if err := c.waitReady(ctx); err != nil {
+			return err
+		}
+		meta, err := c.client.Misc().MetaGetter().Do(ctx)
+		if err != nil {
+			return academy.Classify("read server metadata from "+c.target, err)
+		}
+		version, err := ParseVersion(meta.Version)
+		if err != nil {
+			return academy.Errorf(academy.KindValidation, "preflight", "server %s: %v", c.target, err)
+		}
+		if version.Less(MinServerVersion) {
+			return academy.Errorf(academy.KindValidation, "preflight",
+				"server %s runs Weaviate %s, the client needs at least %s", c.target, version, MinServerVersion)
+		}
+		if TestedServerVersion.Less(Version{version[0], version[1], 0}) {
+			fmt.Fprintf(os.Stderr, "warning: server %s runs Weaviate %s, the commands are tested against %s\n",
+				c.target, version, TestedServerVersion)
+		}
+		c.meta, c.version = meta, version
+	}
+
+	for _, f := range reqs.Features {
+		if since := f.Since(); c.version.Less(since) {
+			return academy.Errorf(academy.KindValidation, "preflight",
+				"server %s runs Weaviate %s, %s needs %s or later", c.target, c.version, f, since)
+		}
+	}
+
+	enabled := EnabledModules(c.meta)
+	var missing []string
+	for _, m := range reqs.Modules {
+		if !hasModule(enabled, m) {
+			missing = append(missing, m)
+		}
+	}
+	if len(missing) > 0 {
+		names := make([]string, 0, len(enabled))
+		for name := range enabled {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return academy.Errorf(academy.KindValidation, "preflight",
+			"server %s does not have module %s enabled (enabled: %s); add it to ENABLE_MODULES",
+			c.target, strings.Join(missing, ", "), strings.Join(names, ", "))
+	}
+
+	return nil
+}
+
+// requireKeys fails if one of modules calls a third party API and the
+// header carrying its key is not configured.
+func (c *Checker) requireKeys(modules []string) error {
+	if c.options.ServerKeys {
+		return nil
+	}
+	for _, m := range modules {
+		header, ok := keyHeader(m)
+		if !ok || hasHeader(c.options.Headers, header) {
+			continue
+		}
+		return academy.Errorf(academy.KindValidation, "preflight",
+			"module %s needs an API key but no %s header is configured (--header, profile headers or e.g. OPENAI_APIKEY); pass --server-side-keys if the server has its own key",
+			m, header)
+	}
+	return nil
+}
+
+// Meta returns the server metadata once Require has succeeded.
+func (c *Checker) Meta() *models.Meta {
+	return c.meta
+}
+
+func (c *Checker) waitReady(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, c.options.Wait)
+	defer cancel()
+
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	var lastErr error
+	for {
+		ready, err := c.client.Misc().ReadyChecker().Do(ctx)
+		if ready {
+			return nil
+		}
+		if err != nil && ctx.Err() == nil {
+			lastErr = err
+		}
+		select {
+		case <-ctx.Done():
+			e := academy.Errorf(academy.KindUnreachable, "preflight", "server %s not ready after %s", c.target, c.options.Wait)
+			if lastErr != nil {
+				e.Msg += ": " + academy.Classify("", lastErr).Error()
+				e.Err = lastErr
+			}
+			return e
+		case <-ticker.C:
+		}
+	}
+}
+
+// EnabledModules returns the modules listed in the server metadata.
+func EnabledModules(meta *models.Meta) map[string]bool {
+	enabled := make(map[string]bool)
+	if modules, ok := meta.Modules.(map[string]interface{}); ok {
+		for name := range modules {
+			enabled[name] = true
+		}
+	}
+	return enabled
+}
+
+func hasModule(enabled map[string]bool, name string) bool {
+	if prefix, ok := strings.CutSuffix(name, "*"); ok {
+		for m := range enabled {
+			if strings.HasPrefix(m, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+	return enabled[name]
+}
@@ -0,0 +1,80 @@
+package preflight
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a Weaviate server version.
+type Version [3]int
+
+// MinServerVersion is the oldest server the weaviate-go-client v4.12.1 API
+// used by the commands works against.
+var MinServerVersion = Version{1, 14, 0}
+
+// TestedServerVersion is the version pinned in docker-compose.yml. Newer
+// minor releases only produce a warning.
+var TestedServerVersion = Version{1, 23, 7}
+
+// Feature is a server capability used by some commands.
+type Feature string
+
+const (
+	FeatureBM25            Feature = "bm25 search"
+	FeatureHybrid          Feature = "hybrid search"
+	FeatureGenerative      Feature = "generative search"
+	FeatureCursor          Feature = "cursor API"
+	FeatureGroupBy         Feature = "Get groupBy"
+	FeaturePQ              Feature = "product quantization"
+	FeatureTokenizationV2  Feature = "text tokenization options"
+	FeaturePropertyIndexes Feature = "null state and property length indexes"
+)
+
+var featureSince = map[Feature]Version{
+	FeaturePropertyIndexes: {1, 16, 0},
+	FeatureBM25:            {1, 17, 0},
+	FeatureHybrid:          {1, 17, 0},
+	FeatureGenerative:      {1, 17, 3},
+	FeatureCursor:          {1, 18, 0},
+	FeaturePQ:              {1, 18, 0},
+	FeatureTokenizationV2:  {1, 19, 0},
+	FeatureGroupBy:         {1, 21, 0},
+}
+
+// Since returns the first server version providing f.
+func (f Feature) Since() Version {
+	return featureSince[f]
+}
+
+// ParseVersion parses "1.23.7", ignoring any pre-release suffix.
+func ParseVersion(s string) (Version, error) {
+	var v Version
+	core, _, _ := strings.Cut(s, "-")
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return v, fmt.Errorf("invalid version %q", s)
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return v, fmt.Errorf("invalid version %q", s)
+		}
+		v[i] = n
+	}
+	return v, nil
+}
+
+// Less reports whether v is older than o.
+func (v Version) Less(o Version) bool {
+	for i := range v {
+		if v[i] != o[i] {
+			return v[i] < o[i]
+		}
+	}
+	return false
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v[0], v[1], v[2])
+}
@@ -0,0 +1,48 @@
+package preflight
+
+import "testing"
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Version
+		err  bool
+	}{
+		{in: "1.23.7", want: Version{1, 23, 7}},
+		{in: "1.4.10", want: Version{1, 4, 10}},
+		{in: "1.24.0-rc.1", want: Version{1, 24, 0}},
+		{in: "", err: true},
+		{in: "1.23", err: true},
+		{in: "1.23.7.1", err: true},
+		{in: "v1.23.7", err: true},
+		{in: "1.x.0", err: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseVersion(tt.in)
+		if (err != nil) != tt.err {
+			t.Errorf("ParseVersion(%q) err = %v, want error %v", tt.in, err, tt.err)
+			continue
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("ParseVersion(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestVersionLess(t *testing.T) {
+	tests := []struct {
+		v, o Version
+		want bool
+	}{
+		{Version{1, 23, 7}, Version{1, 23, 7}, false},
+		{Version{1, 23, 6}, Version{1, 23, 7}, true},
+		{Version{1, 9, 0}, Version{1, 14, 0}, true},
+		{Version{1, 24, 0}, Version{1, 23, 9}, false},
+		{Version{2, 0, 0}, Version{1, 99, 99}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.v.Less(tt.o); got != tt.want {
+			t.Errorf("%v.Less(%v) = %v, want %v", tt.v, tt.o, got, tt.want)
+		}
+	}
+}
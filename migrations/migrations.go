@@ -0,0 +1,18 @@
+// Package migrations embeds the numbered schema migrations run by
+// `schemas_imports migrate`. Each <version>_<name>.yaml file is in the format
+// read by academy.ParseMigration. Never edit a migration that may have been
+// applied somewhere; add a new one instead.
+package migrations
+
+import (
+	"embed"
+	"example.com/weaviate-tutorial/pkg/academy"
+)
+
+//go:embed *.yaml
+var files embed.FS
+
+// Load returns the embedded migrations in version order.
+func Load() ([]*academy.Migration, error) {
+	return academy.LoadMigrations(files)
+}
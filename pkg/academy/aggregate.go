@@ -0,0 +1,65 @@
+package academy
+
+import (
+	"context"
+	"github.com/weaviate/weaviate-go-client/v4/weaviate"
+	"github.com/weaviate/weaviate-go-client/v4/weaviate/filters"
+	"github.com/weaviate/weaviate-go-client/v4/weaviate/graphql"
+	"github.com/weaviate/weaviate/entities/models"
+)
+
+// Aggregation describes an Aggregate query.
+type Aggregation struct {
+	ClassName string
+	Fields    []graphql.Field
+	GroupBy   string
+	Limit     int
+
+	NearText *NearText
+	Where    *filters.WhereBuilder
+}
+
+// Run executes the aggregation, checking the response like Search.Run.
+func (a Aggregation) Run(ctx context.Context, client *weaviate.Client) (*models.GraphQLResponse, error) {
+	op := "aggregate " + a.ClassName
+	agg := client.GraphQL().Aggregate().
+		WithClassName(a.ClassName).
+		WithFields(a.Fields...)
+	if a.GroupBy != "" {
+		agg = agg.WithGroupBy(a.GroupBy)
+	}
+	if a.Limit > 0 {
+		agg = agg.WithLimit(a.Limit)
+	}
+	if a.NearText != nil {
+		agg = agg.WithNearText(a.NearText.builder(client))
+	}
+	if a.Where != nil {
+		agg = agg.WithWhere(a.Where)
+	}
+	res, err := agg.Do(ctx)
+	if err != nil {
+		return nil, Classify(op, err)
+	}
+	return res, CheckResponse(op, res)
+}
+
+// Count returns the number of objects in className.
+func Count(ctx context.Context, client *weaviate.Client, className string) (int, error) {
+	res, err := Aggregation{
+		ClassName: className,
+		Fields:    []graphql.Field{{Name: "meta", Fields: Names("count")}},
+	}.Run(ctx, client)
+	if err != nil {
+		return 0, err
+	}
+	agg, _ := res.Data["Aggregate"].(map[string]interface{})
+	groups, _ := agg[className].([]interface{})
+	if len(groups) == 0 {
+		return 0, nil
+	}
+	group, _ := groups[0].(map[string]interface{})
+	meta, _ := group["meta"].(map[string]interface{})
+	count, _ := meta["count"].(float64)
+	return int(count), nil
+}
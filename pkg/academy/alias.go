@@ -0,0 +1,250 @@
+package academy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/weaviate/weaviate-go-client/v4/weaviate"
+	"github.com/weaviate/weaviate/entities/models"
+	"os"
+	"sort"
+	"time"
+)
+
+// Alias maps a logical name such as questions to a physical class such as
+// JeopardyQuestion_v3. Previous holds the classes it pointed to before, the
+// most recent last.
+type Alias struct {
+	Name     string    `json:"name"`
+	Class    string    `json:"class"`
+	Previous []string  `json:"previous,omitempty"`
+	Updated  time.Time `json:"updated"`
+}
+
+// AliasStore persists aliases.
+type AliasStore interface {
+	Load(ctx context.Context) ([]Alias, error)
+	Save(ctx context.Context, alias Alias) error
+}
+
+// Aliases resolves logical class names. Weaviate 1.23 has no class aliases,
+// so commands resolve names through this registry instead and a reindex
+// becomes: clone into a new class, then switch the alias.
+type Aliases struct {
+	store  AliasStore
+	loaded map[string]Alias
+}
+
+// NewAliases returns a registry backed by store.
+func NewAliases(store AliasStore) *Aliases {
+	return &Aliases{store: store}
+}
+
+func (a *Aliases) load(ctx context.Context) error {
+	if a.loaded != nil {
+		return nil
+	}
+	aliases, err := a.store.Load(ctx)
+	if err != nil {
+		return err
+	}
+	a.loaded = make(map[string]Alias, len(aliases))
+	for _, alias := range aliases {
+		a.loaded[alias.Name] = alias
+	}
+	return nil
+}
+
+// Resolve returns the class name points to, or name itself if it is not an
+// alias.
+func (a *Aliases) Resolve(ctx context.Context, name string) (string, error) {
+	if err := a.load(ctx); err != nil {
+		return "", err
+	}
+	if alias, ok := a.loaded[name]; ok {
+		return alias.Class, nil
+	}
+	return name, nil
+}
+
+// List returns every alias sorted by name.
+func (a *Aliases) List(ctx context.Context) ([]Alias, error) {
+	if err := a.load(ctx); err != nil {
+		return nil, err
+	}
+	aliases := make([]Alias, 0, len(a.loaded))
+	for _, alias := range a.loaded {
+		aliases = append(aliases, alias)
+	}
+	sort.Slice(aliases, func(i, j int) bool { return aliases[i].Name < aliases[j].Name })
+	return aliases, nil
+}
+
+// Switch points name at class, creating the alias if needed. The class it
+// pointed to before is kept for Rollback.
+func (a *Aliases) Switch(ctx context.Context, name, class string) (*Alias, error) {
+	if err := a.load(ctx); err != nil {
+		return nil, err
+	}
+	alias, ok := a.loaded[name]
+	if ok && alias.Class == class {
+		return &alias, nil
+	}
+	if ok {
+		alias.Previous = append(alias.Previous, alias.Class)
+	}
+	alias.Name, alias.Class, alias.Updated = name, class, time.Now().UTC()
+	return a.save(ctx, alias)
+}
+
+// Rollback points name back at the class it pointed to before the last
+// Switch.
+func (a *Aliases) Rollback(ctx context.Context, name string) (*Alias, error) {
+	if err := a.load(ctx); err != nil {
+		return nil, err
+	}
+	alias, ok := a.loaded[name]
+	if !ok {
+		return nil, Errorf(KindClassNotFound, "rollback alias "+name, "no such alias")
+	}
+	if len(alias.Previous) == 0 {
+		return nil, Errorf(KindValidation, "rollback alias "+name, "no previous class")
+	}
+	last := len(alias.Previous) - 1
+	alias.Class, alias.Previous = alias.Previous[last], alias.Previous[:last]
+	alias.Updated = time.Now().UTC()
+	return a.save(ctx, alias)
+}
+
+func (a *Aliases) save(ctx context.Context, alias Alias) (*Alias, error) {
+	if err := a.store.Save(ctx, alias); err != nil {
+		return nil, err
+	}
+	a.loaded[alias.Name] = alias
+	return &alias, nil
+}
+
+// FileAliasStore keeps aliases in a local JSON file, shared by whoever runs
+// the commands from the same directory.
+type FileAliasStore struct {
+	Path string
+}
+
+func (s FileAliasStore) Load(ctx context.Context) ([]Alias, error) {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var aliases []Alias
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return nil, Errorf(KindValidation, "read aliases "+s.Path, "%v", err)
+	}
+	return aliases, nil
+}
+
+func (s FileAliasStore) Save(ctx context.Context, alias Alias) error {
+	aliases, err := s.Load(ctx)
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i := range aliases {
+		if aliases[i].Name == alias.Name {
+			aliases[i], replaced = alias, true
+		}
+	}
+	if !replaced {
+		aliases = append(aliases, alias)
+	}
+	data, err := json.MarshalIndent(aliases, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Write and rename so readers never see a partial file.
+	tmp := s.Path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.Path)
+}
+
+// ClassAliasStore keeps aliases as objects of a small metadata class on the
+// server, so every client of the cluster sees the same mapping. The class is
+// created on the first Save.
+type ClassAliasStore struct {
+	Client    *weaviate.Client
+	ClassName string
+}
+
+// DefaultAliasClass is the metadata class used by ClassAliasStore.
+const DefaultAliasClass = "AcademyAlias"
+
+func (s ClassAliasStore) className() string {
+	if s.ClassName == "" {
+		return DefaultAliasClass
+	}
+	return s.ClassName
+}
+
+func (s ClassAliasStore) Load(ctx context.Context) ([]Alias, error) {
+	exists, err := ClassExists(ctx, s.Client, s.className())
+	if err != nil || !exists {
+		return nil, err
+	}
+	var aliases []Alias
+	err = ScanObjects(ctx, s.Client, s.className(), 100, false, func(o *models.Object) error {
+		var alias Alias
+		b, err := json.Marshal(o.Properties)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(b, &alias); err != nil {
+			return Errorf(KindValidation, "read aliases", "object %s: %v", o.ID, err)
+		}
+		aliases = append(aliases, alias)
+		return nil
+	})
+	return aliases, err
+}
+
+func (s ClassAliasStore) Save(ctx context.Context, alias Alias) error {
+	if _, err := EnsureClass(ctx, s.Client, s.class(), false); err != nil {
+		return err
+	}
+	properties := map[string]interface{}{
+		"name":     alias.Name,
+		"class":    alias.Class,
+		"previous": alias.Previous,
+		"updated":  alias.Updated.Format(time.RFC3339Nano),
+	}
+	if alias.Previous == nil {
+		properties["previous"] = []string{}
+	}
+	// A deterministic ID makes the batch an upsert.
+	_, err := ImportObjects(ctx, s.Client, []*models.Object{{
+		Class:      s.className(),
+		ID:         ObjectID("alias:" + alias.Name),
+		Properties: properties,
+	}})
+	return err
+}
+
+func (s ClassAliasStore) class() *models.Class {
+	text := func(name string) *models.Property {
+		return &models.Property{Name: name, DataType: []string{"text"}, Tokenization: models.PropertyTokenizationField}
+	}
+	return &models.Class{
+		Class:       s.className(),
+		Description: "Client side class aliases of the academy commands",
+		Vectorizer:  "none",
+		Properties: []*models.Property{
+			text("name"),
+			text("class"),
+			{Name: "previous", DataType: []string{"text[]"}, Tokenization: models.PropertyTokenizationField},
+			{Name: "updated", DataType: []string{"date"}},
+		},
+	}
+}
@@ -0,0 +1,212 @@
+package academy
+
+import (
+	"context"
+	"fmt"
+	"github.com/weaviate/weaviate-go-client/v4/weaviate"
+	"github.com/weaviate/weaviate/entities/models"
+	"strings"
+)
+
+// BM25Params is one setting compared by TuneBM25. A nil K1, B or Stopwords
+// keeps the value of the source class; B may be 0 to turn off length
+// normalisation.
+type BM25Params struct {
+	K1        *float32               `json:"k1,omitempty"`
+	B         *float32               `json:"b,omitempty"`
+	Stopwords *models.StopwordConfig `json:"stopwords,omitempty"`
+}
+
+func (p BM25Params) String() string {
+	var parts []string
+	if p.K1 != nil {
+		parts = append(parts, fmt.Sprintf("k1=%g", *p.K1))
+	}
+	if p.B != nil {
+		parts = append(parts, fmt.Sprintf("b=%g", *p.B))
+	}
+	if sw := p.Stopwords; sw != nil {
+		parts = append(parts, "stopwords="+sw.Preset)
+		for _, w := range sw.Additions {
+			parts = append(parts, "+"+w)
+		}
+		for _, w := range sw.Removals {
+			parts = append(parts, "-"+w)
+		}
+	}
+	if len(parts) == 0 {
+		return "source"
+	}
+	return strings.Join(parts, " ")
+}
+
+// TuneOptions describe a BM25 comparison. Every variant is a temporary copy
+// of Source without vectors, searched with the same Queries; Label is the
+// property shown for each hit.
+type TuneOptions struct {
+	Source     string
+	Variants   []BM25Params
+	Queries    []string
+	Properties []string
+	Label      string
+	Limit      int
+	BatchSize  int
+	// Keep leaves the variant classes in place, e.g. to query them by hand.
+	Keep bool
+}
+
+// TuneRow is the top-k of one variant for one query. Overlap is the share of
+// the source class's top-k the variant also returns; the source rows have
+// Variant "baseline" and an overlap of 1.
+type TuneRow struct {
+	Query   string   `json:"query"`
+	Variant string   `json:"variant"`
+	Class   string   `json:"class"`
+	Overlap float64  `json:"overlap"`
+	Hits    []string `json:"hits"`
+}
+
+type tuneHit struct {
+	ID    string
+	Label string
+}
+
+// TuneBM25 copies opts.Source into one class per variant, runs every query
+// against the source and each copy and returns the rows query by query. The
+// copies are deleted afterwards unless opts.Keep is set. Objects keep their
+// IDs, so the top-k lists can be compared.
+func TuneBM25(ctx context.Context, client *weaviate.Client, opts TuneOptions) (rows []TuneRow, err error) {
+	if opts.BatchSize <= 0 {
+		return nil, Errorf(KindValidation, "tune bm25 "+opts.Source, "batch size must be at least 1, got %d", opts.BatchSize)
+	}
+	source, err := GetClass(ctx, client, opts.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	variants := make([]*models.Class, len(opts.Variants))
+	for i, params := range opts.Variants {
+		variants[i] = bm25Variant(source, fmt.Sprintf("%s_Bm25Tune%d", opts.Source, i+1), params)
+		if err := checkInvertedIndex(variants[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	classes := []string{opts.Source}
+	names := []string{"baseline"}
+	defer func() {
+		if opts.Keep {
+			return
+		}
+		for _, className := range classes[1:] {
+			if derr := DeleteClass(ctx, client, className); derr != nil && err == nil {
+				err = derr
+			}
+		}
+	}()
+	for i, variant := range variants {
+		_, err := CloneClass(ctx, client, CloneOptions{
+			Source:    opts.Source,
+			Target:    variant,
+			BatchSize: opts.BatchSize,
+			KeepIDs:   true,
+		})
+		if KindOf(err) != KindAlreadyExists {
+			// Created, even if the copy failed, so it must be cleaned up.
+			classes = append(classes, variant.Class)
+		}
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, opts.Variants[i].String())
+	}
+
+	for _, query := range opts.Queries {
+		var baseline map[string]bool
+		for i, className := range classes {
+			hits, err := bm25Hits(ctx, client, className, query, opts)
+			if err != nil {
+				return nil, err
+			}
+			row := TuneRow{Query: query, Variant: names[i], Class: className, Overlap: 1}
+			if i == 0 {
+				baseline = map[string]bool{}
+				for _, h := range hits {
+					baseline[h.ID] = true
+				}
+			} else if len(baseline) > 0 {
+				shared := 0
+				for _, h := range hits {
+					if baseline[h.ID] {
+						shared++
+					}
+				}
+				row.Overlap = float64(shared) / float64(len(baseline))
+			}
+			for _, h := range hits {
+				row.Hits = append(row.Hits, h.Label)
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+// bm25Variant returns a copy of source named className with params applied.
+// Vectorizer and module settings are dropped: BM25 does not use vectors, and
+// the copy is quicker without them.
+func bm25Variant(source *models.Class, className string, params BM25Params) *models.Class {
+	inv := models.InvertedIndexConfig{}
+	if source.InvertedIndexConfig != nil {
+		inv = *source.InvertedIndexConfig
+	}
+	bm25 := models.BM25Config{}
+	if inv.Bm25 != nil {
+		bm25 = *inv.Bm25
+	}
+	if params.K1 != nil {
+		bm25.K1 = *params.K1
+	}
+	if params.B != nil {
+		bm25.B = *params.B
+	}
+	inv.Bm25 = &bm25
+	if params.Stopwords != nil {
+		inv.Stopwords = params.Stopwords
+	}
+
+	properties := make([]*models.Property, len(source.Properties))
+	for i, p := range source.Properties {
+		copied := *p
+		copied.ModuleConfig = nil
+		properties[i] = &copied
+	}
+	return &models.Class{
+		Class:               className,
+		Description:         "Temporary BM25 variant of " + source.Class,
+		Vectorizer:          "none",
+		InvertedIndexConfig: &inv,
+		Properties:          properties,
+	}
+}
+
+func bm25Hits(ctx context.Context, client *weaviate.Client, className, query string, opts TuneOptions) ([]tuneHit, error) {
+	search := Search{
+		ClassName:  className,
+		Properties: []string{opts.Label},
+		Additional: Names("id"),
+		Limit:      opts.Limit,
+		BM25:       &BM25{Query: query, Properties: opts.Properties},
+	}
+	objects, err := SearchRows[map[string]interface{}](ctx, client, search)
+	if err != nil {
+		return nil, err
+	}
+	hits := make([]tuneHit, len(objects))
+	for i, o := range objects {
+		additional, _ := o["_additional"].(map[string]interface{})
+		hits[i].ID, _ = additional["id"].(string)
+		hits[i].Label = fmt.Sprint(o[opts.Label])
+	}
+	return hits, nil
+}
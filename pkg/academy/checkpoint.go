@@ -0,0 +1,121 @@
+package academy
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Checkpoint records how far the import of File into Class got: the first
+// Index elements, which end at byte Offset, were sent in batches the server
+// acknowledged. Batches counts the batches completed so far. Size and
+// ModTime identify the version of File it applies to.
+//
+// Batches in flight when the import stopped are sent again on resume; objects
+// with deterministic IDs, see ObjectID, are then updated rather than
+// duplicated.
+type Checkpoint struct {
+	File    string    `json:"file"`
+	Class   string    `json:"class"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+	Index   int       `json:"index"`
+	Offset  int64     `json:"offset"`
+	Batches int       `json:"batches"`
+	Updated time.Time `json:"updated"`
+}
+
+// NewCheckpoint returns the checkpoint at the start of importing file into
+// className. File is made absolute, so the import can resume from another
+// directory.
+func NewCheckpoint(file, className string) (*Checkpoint, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, err
+	}
+	if abs, err := filepath.Abs(file); err == nil {
+		file = abs
+	}
+	return &Checkpoint{
+		File:    file,
+		Class:   className,
+		Size:    info.Size(),
+		ModTime: info.ModTime().UTC(),
+	}, nil
+}
+
+// ReadCheckpoint reads the checkpoint written to path, returning nil if
+// there is none.
+func ReadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, Errorf(KindValidation, "read checkpoint "+path, "%v", err)
+	}
+	return &cp, nil
+}
+
+// Resumes checks that c, as read back, continues the import of file into
+// className and that file has not changed since.
+func (c *Checkpoint) Resumes(file, className string) error {
+	op := "resume import of " + file
+	start, err := NewCheckpoint(file, className)
+	if err != nil {
+		return err
+	}
+	switch {
+	case !samePath(c.File, file):
+		return Errorf(KindValidation, op, "the checkpoint is for %s", c.File)
+	case c.Class != className:
+		return Errorf(KindValidation, op, "the checkpoint is for class %s, not %s", c.Class, className)
+	case c.Size != start.Size || !c.ModTime.Equal(start.ModTime):
+		return Errorf(KindValidation, op, "the file changed since the checkpoint of %s", c.Updated.Format(time.RFC3339))
+	case c.Offset > c.Size:
+		return Errorf(KindValidation, op, "the checkpoint offset %d is past the end of the file", c.Offset)
+	}
+	return nil
+}
+
+// samePath reports whether a and b name the same file, relative paths being
+// taken from the working directory.
+func samePath(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	if errA != nil || errB != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+	return absA == absB
+}
+
+// Advance moves c to what stats acknowledged, for an import that started at
+// from.
+func (c *Checkpoint) Advance(from Checkpoint, stats ImportStats) {
+	c.Index = stats.Acknowledged
+	if stats.Acknowledged > from.Index {
+		c.Offset = stats.Position
+	}
+	c.Batches = from.Batches + stats.Batches
+	c.Updated = time.Now().UTC()
+}
+
+// Write saves c to path.
+func (c *Checkpoint) Write(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Write and rename so a crash never leaves a partial checkpoint.
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
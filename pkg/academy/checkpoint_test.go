@@ -0,0 +1,186 @@
+package academy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckpointAdvance(t *testing.T) {
+	tests := []struct {
+		name  string
+		from  Checkpoint
+		stats ImportStats
+		want  Checkpoint
+	}{
+		{
+			name:  "fresh",
+			stats: ImportStats{Acknowledged: 300, Position: 9000, Batches: 3},
+			want:  Checkpoint{Index: 300, Offset: 9000, Batches: 3},
+		},
+		{
+			name:  "resumed",
+			from:  Checkpoint{Index: 300, Offset: 9000, Batches: 3},
+			stats: ImportStats{Acknowledged: 500, Position: 15000, Batches: 2},
+			want:  Checkpoint{Index: 500, Offset: 15000, Batches: 5},
+		},
+		{
+			name:  "resumed without progress",
+			from:  Checkpoint{Index: 300, Offset: 9000, Batches: 3},
+			stats: ImportStats{Acknowledged: 300},
+			want:  Checkpoint{Index: 300, Offset: 9000, Batches: 3},
+		},
+		{
+			name:  "batches past a gap",
+			from:  Checkpoint{Index: 300, Offset: 9000, Batches: 3},
+			stats: ImportStats{Acknowledged: 300, Batches: 4},
+			want:  Checkpoint{Index: 300, Offset: 9000, Batches: 7},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.from
+			c.Advance(tt.from, tt.stats)
+			if c.Updated.IsZero() {
+				t.Error("Updated not set")
+			}
+			if c.Index != tt.want.Index || c.Offset != tt.want.Offset || c.Batches != tt.want.Batches {
+				t.Errorf("index %d, offset %d, batches %d, want %d, %d, %d",
+					c.Index, c.Offset, c.Batches, tt.want.Index, tt.want.Offset, tt.want.Batches)
+			}
+		})
+	}
+}
+
+func TestCheckpointResumes(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		// change alters the checkpoint or file and returns the file to
+		// resume.
+		change func(t *testing.T, file string, c *Checkpoint) string
+		// err is part of the error message, empty if it resumes.
+		err string
+	}{
+		{
+			name:   "unchanged",
+			change: func(t *testing.T, file string, c *Checkpoint) string { return file },
+		},
+		{
+			name: "relative path",
+			change: func(t *testing.T, file string, c *Checkpoint) string {
+				rel, err := filepath.Rel(wd, file)
+				if err != nil {
+					t.Skip(err)
+				}
+				return rel
+			},
+		},
+		{
+			name: "other file",
+			change: func(t *testing.T, file string, c *Checkpoint) string {
+				other := filepath.Join(filepath.Dir(file), "other.json")
+				copyFile(t, file, other)
+				return other
+			},
+			err: "the checkpoint is for /",
+		},
+		{
+			name: "other class",
+			change: func(t *testing.T, file string, c *Checkpoint) string {
+				c.Class = "Other"
+				return file
+			},
+			err: "for class Other, not Test",
+		},
+		{
+			name: "file grew",
+			change: func(t *testing.T, file string, c *Checkpoint) string {
+				info, _ := os.Stat(file)
+				if err := os.WriteFile(file, []byte(`[1, 2, 3, 4]`), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				// Keep the modification time to catch the size alone.
+				if err := os.Chtimes(file, info.ModTime(), info.ModTime()); err != nil {
+					t.Fatal(err)
+				}
+				return file
+			},
+			err: "the file changed",
+		},
+		{
+			name: "file touched",
+			change: func(t *testing.T, file string, c *Checkpoint) string {
+				later := c.ModTime.Add(time.Minute)
+				if err := os.Chtimes(file, later, later); err != nil {
+					t.Fatal(err)
+				}
+				return file
+			},
+			err: "the file changed",
+		},
+		{
+			name: "offset past the end",
+			change: func(t *testing.T, file string, c *Checkpoint) string {
+				c.Offset = c.Size + 1
+				return file
+			},
+			err: "past the end of the file",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "data.json")
+			if err := os.WriteFile(file, []byte(`[1, 2, 3]`), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			c, err := NewCheckpoint(file, "Test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			c.Index, c.Offset = 2, 5
+
+			// Resume from the checkpoint as written.
+			path := filepath.Join(t.TempDir(), "checkpoint.json")
+			if err := c.Write(path); err != nil {
+				t.Fatal(err)
+			}
+			read, err := ReadCheckpoint(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resume := tt.change(t, file, read)
+
+			err = read.Resumes(resume, "Test")
+			switch {
+			case tt.err == "" && err != nil:
+				t.Errorf("err = %v", err)
+			case tt.err != "" && (KindOf(err) != KindValidation || !strings.Contains(err.Error(), tt.err)):
+				t.Errorf("err = %v, want a validation error with %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestReadCheckpointMissing(t *testing.T) {
+	c, err := ReadCheckpoint(filepath.Join(t.TempDir(), "none.json"))
+	if c != nil || err != nil {
+		t.Errorf("got %v, %v, want no checkpoint", c, err)
+	}
+}
+
+func copyFile(t *testing.T, from, to string) {
+	t.Helper()
+	data, err := os.ReadFile(from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(to, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
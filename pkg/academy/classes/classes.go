@@ -0,0 +1,29 @@
+// Package classes holds the Go types and property names of the tutorial
+// classes, generated from their schema by cmd/schemagen. Use the property
+// constants instead of string literals so that a renamed property breaks the
+// build instead of the query.
+package classes
+
+import (
+	"github.com/weaviate/weaviate-go-client/v4/weaviate/graphql"
+)
+
+//go:generate go run ../../../cmd/schemagen --from ../../../schemas --from ../../../schemas/edu-demo --class JeopardyQuestion,WikiCity,Article --package classes --out classes_gen.go
+
+// Names converts typed property names for academy.Search.Properties.
+func Names[P ~string](props ...P) []string {
+	names := make([]string, len(props))
+	for i, p := range props {
+		names[i] = string(p)
+	}
+	return names
+}
+
+// Fields selects props in a query.
+func Fields[P ~string](props ...P) []graphql.Field {
+	fields := make([]graphql.Field, len(props))
+	for i, p := range props {
+		fields[i] = graphql.Field{Name: string(p)}
+	}
+	return fields
+}
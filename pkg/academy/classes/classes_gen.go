@@ -0,0 +1,106 @@
+// Code generated by schemagen from ../../../schemas, ../../../schemas/edu-demo. DO NOT EDIT.
+
+package classes
+
+import (
+	"example.com/weaviate-tutorial/pkg/academy"
+)
+
+// JeopardyQuestion is an object of the JeopardyQuestion class.
+type JeopardyQuestion struct {
+	Round    string `json:"round,omitempty"`
+	Value    int    `json:"value,omitempty"`
+	Question string `json:"question,omitempty"`
+	Answer   string `json:"answer,omitempty"`
+	Points   int    `json:"points,omitempty"`
+
+	academy.Additional `json:"_additional"`
+}
+
+// JeopardyQuestionProperty names a property of JeopardyQuestion.
+type JeopardyQuestionProperty string
+
+// Properties of JeopardyQuestion.
+const (
+	JeopardyQuestionRound    JeopardyQuestionProperty = "round"    // text
+	JeopardyQuestionValue    JeopardyQuestionProperty = "value"    // int
+	JeopardyQuestionQuestion JeopardyQuestionProperty = "question" // text
+	JeopardyQuestionAnswer   JeopardyQuestionProperty = "answer"   // text
+	JeopardyQuestionPoints   JeopardyQuestionProperty = "points"   // int
+)
+
+// JeopardyQuestionProperties lists every property of JeopardyQuestion in schema order.
+var JeopardyQuestionProperties = []JeopardyQuestionProperty{
+	JeopardyQuestionRound,
+	JeopardyQuestionValue,
+	JeopardyQuestionQuestion,
+	JeopardyQuestionAnswer,
+	JeopardyQuestionPoints,
+}
+
+// JeopardyQuestionFields selects every property of JeopardyQuestion.
+var JeopardyQuestionFields = Fields(JeopardyQuestionProperties...)
+
+// WikiCity is an object of the WikiCity class.
+type WikiCity struct {
+	CityName    string  `json:"city_name,omitempty"`
+	Country     string  `json:"country,omitempty"`
+	Lat         float64 `json:"lat,omitempty"`
+	Lng         float64 `json:"lng,omitempty"`
+	WikiSummary string  `json:"wiki_summary,omitempty"`
+
+	academy.Additional `json:"_additional"`
+}
+
+// WikiCityProperty names a property of WikiCity.
+type WikiCityProperty string
+
+// Properties of WikiCity.
+const (
+	WikiCityCityName    WikiCityProperty = "city_name"    // text
+	WikiCityCountry     WikiCityProperty = "country"      // text
+	WikiCityLat         WikiCityProperty = "lat"          // number
+	WikiCityLng         WikiCityProperty = "lng"          // number
+	WikiCityWikiSummary WikiCityProperty = "wiki_summary" // text
+)
+
+// WikiCityProperties lists every property of WikiCity in schema order.
+var WikiCityProperties = []WikiCityProperty{
+	WikiCityCityName,
+	WikiCityCountry,
+	WikiCityLat,
+	WikiCityLng,
+	WikiCityWikiSummary,
+}
+
+// WikiCityFields selects every property of WikiCity.
+var WikiCityFields = Fields(WikiCityProperties...)
+
+// Article is an object of the Article class.
+type Article struct {
+	Title string `json:"title,omitempty"`
+	Body  string `json:"body,omitempty"`
+	URL   string `json:"url,omitempty"`
+
+	academy.Additional `json:"_additional"`
+}
+
+// ArticleProperty names a property of Article.
+type ArticleProperty string
+
+// Properties of Article.
+const (
+	ArticleTitle ArticleProperty = "title" // text
+	ArticleBody  ArticleProperty = "body"  // text
+	ArticleURL   ArticleProperty = "url"   // text
+)
+
+// ArticleProperties lists every property of Article in schema order.
+var ArticleProperties = []ArticleProperty{
+	ArticleTitle,
+	ArticleBody,
+	ArticleURL,
+}
+
+// ArticleFields selects every property of Article.
+var ArticleFields = Fields(ArticleProperties...)
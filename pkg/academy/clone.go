@@ -0,0 +1,92 @@
+package academy
+
+import (
+	"context"
+	"github.com/go-openapi/strfmt"
+	"github.com/weaviate/weaviate-go-client/v4/weaviate"
+	"github.com/weaviate/weaviate/entities/models"
+)
+
+// CloneOptions describe a class copy. Target is created from its definition
+// before any object is copied. Vectors should only be kept when Target uses
+// the source's vectorizer; otherwise the objects are vectorized again.
+type CloneOptions struct {
+	Source      string
+	Target      *models.Class
+	BatchSize   int
+	KeepIDs     bool
+	KeepVectors bool
+}
+
+// CloneResult reports a class copy. Failed indexes count objects in source
+// order and carry the source object ID.
+type CloneResult struct {
+	Source string         `json:"source"`
+	Target string         `json:"target"`
+	Copied int            `json:"copied"`
+	Failed []BatchFailure `json:"failed,omitempty"`
+}
+
+// CloneClass creates opts.Target and copies every object of opts.Source into
+// it, reading with the cursor API and writing in batches. Objects the server
+// rejects are collected in the result, which is returned together with a
+// KindPartialBatch error.
+func CloneClass(ctx context.Context, client *weaviate.Client, opts CloneOptions) (*CloneResult, error) {
+	if opts.BatchSize <= 0 {
+		return nil, Errorf(KindValidation, "clone "+opts.Source, "batch size must be at least 1, got %d", opts.BatchSize)
+	}
+	result := &CloneResult{Source: opts.Source, Target: opts.Target.Class}
+	if err := CreateClass(ctx, client, opts.Target); err != nil {
+		return nil, err
+	}
+
+	var batch []*models.Object
+	var sourceIDs []strfmt.UUID
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		res, err := ImportObjects(ctx, client, batch)
+		if res == nil {
+			return err
+		}
+		offset := result.Copied + len(result.Failed)
+		for _, f := range res.Failed {
+			f.ID = sourceIDs[f.Index]
+			f.Index += offset
+			result.Failed = append(result.Failed, f)
+		}
+		result.Copied += len(batch) - len(res.Failed)
+		batch, sourceIDs = batch[:0], sourceIDs[:0]
+		return nil
+	}
+
+	err := ScanObjects(ctx, client, opts.Source, opts.BatchSize, opts.KeepVectors, func(o *models.Object) error {
+		copied := &models.Object{
+			Class:      opts.Target.Class,
+			Properties: o.Properties,
+		}
+		if opts.KeepIDs {
+			copied.ID = o.ID
+		}
+		if opts.KeepVectors {
+			copied.Vector = o.Vector
+		}
+		batch = append(batch, copied)
+		sourceIDs = append(sourceIDs, o.ID)
+		if len(batch) < opts.BatchSize {
+			return nil
+		}
+		return flush()
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		return result, err
+	}
+	if len(result.Failed) > 0 {
+		return result, Errorf(KindPartialBatch, "clone "+opts.Source, "%d of %d objects failed", len(result.Failed), result.Copied+len(result.Failed))
+	}
+	return result, nil
+}
@@ -0,0 +1,78 @@
+package academy
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/weaviate/weaviate-go-client/v4/weaviate/graphql"
+	"github.com/weaviate/weaviate/entities/models"
+)
+
+// Additional holds the _additional fields Weaviate can return with an
+// object. Embed it in a row type with the tag `json:"_additional"`.
+type Additional struct {
+	ID           string    `json:"id,omitempty"`
+	Distance     *float64  `json:"distance,omitempty"`
+	Certainty    *float64  `json:"certainty,omitempty"`
+	Score        string    `json:"score,omitempty"`
+	ExplainScore string    `json:"explainScore,omitempty"`
+	Vector       []float32 `json:"vector,omitempty"`
+	Generate     *Generate `json:"generate,omitempty"`
+	Answer       *Answer   `json:"answer,omitempty"`
+}
+
+// Generate is the result of a generative search for one object. Grouped
+// results are only set on the first object.
+type Generate struct {
+	SingleResult  *string `json:"singleResult,omitempty"`
+	GroupedResult *string `json:"groupedResult,omitempty"`
+	Error         *string `json:"error,omitempty"`
+}
+
+// Answer is the result of an ask (qna) search for one object.
+type Answer struct {
+	HasAnswer bool    `json:"hasAnswer"`
+	Property  string  `json:"property,omitempty"`
+	Result    string  `json:"result,omitempty"`
+	Certainty float64 `json:"certainty,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// DecodeGet decodes the objects of className in the Get section of res
+// into rows of type T, matching properties by their json tags.
+func DecodeGet[T any](res *models.GraphQLResponse, className string) ([]T, error) {
+	const op = "decode results"
+	if res == nil {
+		return nil, nil
+	}
+	get, _ := res.Data["Get"].(map[string]interface{})
+	objects, ok := get[className]
+	if !ok {
+		return nil, Errorf(KindGraphQL, op, "response has no Get results for class %s", className)
+	}
+	b, err := json.Marshal(objects)
+	if err != nil {
+		return nil, &Error{Kind: KindUnknown, Op: op, Msg: err.Error(), Err: err}
+	}
+	var rows []T
+	if err := json.Unmarshal(b, &rows); err != nil {
+		return nil, &Error{Kind: KindValidation, Op: op, Msg: err.Error(), Err: err}
+	}
+	return rows, nil
+}
+
+// Get runs get, which must query className, checks the response for errors
+// and decodes the returned objects into rows of type T. Like Search.Run it
+// returns the rows of a response carrying errors together with the error.
+func Get[T any](ctx context.Context, get *graphql.GetBuilder, className string) ([]T, error) {
+	op := "get " + className
+	res, err := get.Do(ctx)
+	if err != nil {
+		return nil, Classify(op, err)
+	}
+	checkErr := CheckResponse(op, res)
+	rows, err := DecodeGet[T](res, className)
+	if checkErr != nil {
+		return rows, checkErr
+	}
+	return rows, err
+}
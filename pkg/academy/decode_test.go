@@ -0,0 +1,89 @@
+package academy
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testRow struct {
+	Name  string   `json:"name"`
+	Count int      `json:"count,omitempty"`
+	Tags  []string `json:"tags,omitempty"`
+
+	Additional `json:"_additional"`
+}
+
+func TestDecodeGet(t *testing.T) {
+	distance := 0.25
+	tests := []struct {
+		name string
+		body string
+		want []testRow
+		kind Kind
+	}{
+		{
+			name: "rows",
+			body: `{"data": {"Get": {"Test": [
+				{"name": "a", "count": 2, "tags": ["x", "y"], "_additional": {"id": "1", "distance": 0.25}},
+				{"name": "b", "extra": true}
+			]}}}`,
+			want: []testRow{
+				{Name: "a", Count: 2, Tags: []string{"x", "y"}, Additional: Additional{ID: "1", Distance: &distance}},
+				{Name: "b"},
+			},
+		},
+		{
+			name: "generate",
+			body: `{"data": {"Get": {"Test": [{"name": "a", "_additional": {"generate": {"singleResult": "tweet"}}}]}}}`,
+			want: []testRow{{Name: "a", Additional: Additional{Generate: &Generate{SingleResult: strPtr("tweet")}}}},
+		},
+		{
+			name: "no objects",
+			body: `{"data": {"Get": {"Test": []}}}`,
+			want: []testRow{},
+		},
+		{
+			name: "null objects",
+			body: `{"data": {"Get": {"Test": null}}}`,
+		},
+		{
+			name: "other class",
+			body: `{"data": {"Get": {"Other": []}}}`,
+			kind: KindGraphQL,
+		},
+		{
+			name: "no data",
+			body: `{"errors": [{"message": "boom"}]}`,
+			kind: KindGraphQL,
+		},
+		{
+			name: "wrong type",
+			body: `{"data": {"Get": {"Test": [{"name": "a", "count": "many"}]}}}`,
+			kind: KindValidation,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := DecodeGet[testRow](response(t, tt.body), "Test")
+			if tt.kind != KindUnknown {
+				if KindOf(err) != tt.kind {
+					t.Fatalf("err = %v, want kind %v", err, tt.kind)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(rows, tt.want) {
+				t.Errorf("got %+v, want %+v", rows, tt.want)
+			}
+		})
+	}
+
+	rows, err := DecodeGet[testRow](nil, "Test")
+	if rows != nil || err != nil {
+		t.Errorf("DecodeGet(nil) = %v, %v", rows, err)
+	}
+}
+
+func strPtr(s string) *string { return &s }
@@ -0,0 +1,396 @@
+package academy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/weaviate/weaviate-go-client/v4/weaviate"
+	"github.com/weaviate/weaviate/entities/models"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Action is what a Change does to the live schema.
+type Action string
+
+const (
+	ActionCreateClass    Action = "create-class"
+	ActionAddProperty    Action = "add-property"
+	ActionRemoveProperty Action = "remove-property"
+	ActionChange         Action = "change"
+)
+
+// Change is a difference between a desired class and the live one. Field is
+// the changed setting, e.g. tokenization or vectorIndexConfig.ef, and Want
+// and Have are its JSON encoded values. Additive changes, new classes and
+// properties or settings the server can update, can be applied in place; the
+// others need the class to be reindexed.
+type Change struct {
+	Class    string `json:"class"`
+	Property string `json:"property,omitempty"`
+	Action   Action `json:"action"`
+	Field    string `json:"field,omitempty"`
+	Want     string `json:"want,omitempty"`
+	Have     string `json:"have,omitempty"`
+	Additive bool   `json:"additive"`
+}
+
+func (c Change) String() string {
+	target := c.Class
+	if c.Property != "" {
+		target += "." + c.Property
+	}
+	if c.Field != "" {
+		return fmt.Sprintf("%s %s %s: %s -> %s", c.Action, target, c.Field, orNone(c.Have), orNone(c.Want))
+	}
+	return fmt.Sprintf("%s %s", c.Action, target)
+}
+
+func orNone(v string) string {
+	if v == "" {
+		return "(unset)"
+	}
+	return v
+}
+
+// SchemaDiff lists the changes needed to turn the live schema into the
+// desired one.
+type SchemaDiff struct {
+	Changes []Change `json:"changes"`
+}
+
+// Incompatible returns the changes that cannot be applied in place.
+func (d *SchemaDiff) Incompatible() []Change {
+	var changes []Change
+	for _, c := range d.Changes {
+		if !c.Additive {
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+// ReindexPlan describes how to move each class with incompatible changes to
+// its desired definition.
+func (d *SchemaDiff) ReindexPlan() []string {
+	var plan []string
+	seen := map[string]bool{}
+	for _, c := range d.Incompatible() {
+		if seen[c.Class] {
+			continue
+		}
+		seen[c.Class] = true
+		plan = append(plan,
+			fmt.Sprintf("%s: create a new class from the desired definition", c.Class),
+			fmt.Sprintf("%s: copy its objects into the new class, re-vectorizing if the vectorizer changed", c.Class),
+			fmt.Sprintf("%s: point readers at the new class, then delete %s", c.Class, c.Class),
+		)
+	}
+	return plan
+}
+
+// DiffSchema compares the desired classes with the live schema. Only classes
+// in want are compared, and only settings want specifies: values the server
+// filled in with defaults do not count as changes.
+func DiffSchema(want []*models.Class, have []*models.Class) (*SchemaDiff, error) {
+	live := map[string]*models.Class{}
+	for _, c := range have {
+		live[c.Class] = c
+	}
+	diff := &SchemaDiff{}
+	for _, w := range want {
+		h, ok := live[w.Class]
+		if !ok {
+			diff.Changes = append(diff.Changes, Change{Class: w.Class, Action: ActionCreateClass, Additive: true})
+			continue
+		}
+		changes, err := diffClass(w, h)
+		if err != nil {
+			return nil, err
+		}
+		diff.Changes = append(diff.Changes, changes...)
+	}
+	return diff, nil
+}
+
+// classSkip are class keys compared separately or not at all.
+var classSkip = map[string]bool{"class": true, "properties": true, "description": true}
+
+// propertySkip are property keys that cannot change the stored data.
+var propertySkip = map[string]bool{"name": true, "description": true}
+
+// mutableFields are the class settings the server updates in place.
+var mutableFields = []string{
+	"invertedIndexConfig.bm25",
+	"invertedIndexConfig.stopwords",
+	"invertedIndexConfig.cleanupIntervalSeconds",
+	"vectorIndexConfig.ef",
+	"vectorIndexConfig.dynamicEfMin",
+	"vectorIndexConfig.dynamicEfMax",
+	"vectorIndexConfig.dynamicEfFactor",
+	"vectorIndexConfig.flatSearchCutoff",
+	"vectorIndexConfig.vectorCacheMaxObjects",
+	"vectorIndexConfig.cleanupIntervalSeconds",
+	"vectorIndexConfig.pq",
+}
+
+func mutable(field string) bool {
+	for _, f := range mutableFields {
+		if field == f || strings.HasPrefix(field, f+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func diffClass(want, have *models.Class) ([]Change, error) {
+	w, err := genericMap(want)
+	if err != nil {
+		return nil, err
+	}
+	h, err := genericMap(have)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+	for _, field := range subsetDiff("", w, h, classSkip) {
+		changes = append(changes, Change{
+			Class:    want.Class,
+			Action:   ActionChange,
+			Field:    field,
+			Want:     encode(lookup(w, field)),
+			Have:     encode(lookup(h, field)),
+			Additive: mutable(field),
+		})
+	}
+
+	props := map[string]*models.Property{}
+	for _, p := range have.Properties {
+		props[p.Name] = p
+	}
+	wanted := map[string]bool{}
+	for _, wp := range want.Properties {
+		wanted[wp.Name] = true
+		hp, ok := props[wp.Name]
+		if !ok {
+			changes = append(changes, Change{Class: want.Class, Property: wp.Name, Action: ActionAddProperty, Additive: true})
+			continue
+		}
+		w, err := genericMap(wp)
+		if err != nil {
+			return nil, err
+		}
+		h, err := genericMap(hp)
+		if err != nil {
+			return nil, err
+		}
+		for _, field := range subsetDiff("", w, h, propertySkip) {
+			changes = append(changes, Change{
+				Class:    want.Class,
+				Property: wp.Name,
+				Action:   ActionChange,
+				Field:    field,
+				Want:     encode(lookup(w, field)),
+				Have:     encode(lookup(h, field)),
+			})
+		}
+	}
+	// A class without properties relies on auto-schema, so extra live
+	// properties are only reported when the desired class lists some.
+	if len(want.Properties) > 0 {
+		for _, hp := range have.Properties {
+			if !wanted[hp.Name] {
+				changes = append(changes, Change{Class: want.Class, Property: hp.Name, Action: ActionRemoveProperty})
+			}
+		}
+	}
+	return changes, nil
+}
+
+// subsetDiff returns the dotted paths of the values in want that differ from
+// have, descending into nested objects.
+func subsetDiff(prefix string, want, have map[string]interface{}, skip map[string]bool) []string {
+	var fields []string
+	for _, k := range sortedMapKeys(want) {
+		if prefix == "" && skip[k] {
+			continue
+		}
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		wv, hv := want[k], have[k]
+		wm, wok := wv.(map[string]interface{})
+		hm, hok := hv.(map[string]interface{})
+		switch {
+		case wok && hok:
+			fields = append(fields, subsetDiff(path, wm, hm, nil)...)
+		case wok && len(wm) == 0 && hv == nil:
+			// An empty object enables a module with its defaults.
+		case !reflect.DeepEqual(wv, hv):
+			fields = append(fields, path)
+		}
+	}
+	return fields
+}
+
+func genericMap(v interface{}) (map[string]interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	return m, json.Unmarshal(b, &m)
+}
+
+func lookup(m map[string]interface{}, path string) interface{} {
+	var v interface{} = m
+	for _, k := range strings.Split(path, ".") {
+		mv, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = mv[k]
+	}
+	return v
+}
+
+func encode(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+func sortedMapKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ApplyDiff creates the missing classes and properties of diff and updates
+// the changed mutable settings. It refuses, without changing anything, when
+// diff holds changes that need a reindex.
+func ApplyDiff(ctx context.Context, client *weaviate.Client, want []*models.Class, diff *SchemaDiff) error {
+	if incompatible := diff.Incompatible(); len(incompatible) > 0 {
+		return Errorf(KindValidation, "apply schema", "%d change(s) need a reindex, first: %s", len(incompatible), incompatible[0])
+	}
+	classes := map[string]*models.Class{}
+	for _, c := range want {
+		classes[c.Class] = c
+	}
+	updates := map[string][]string{}
+	var updated []string
+	for _, c := range diff.Changes {
+		switch c.Action {
+		case ActionCreateClass:
+			if err := CreateClass(ctx, client, classes[c.Class]); err != nil {
+				return err
+			}
+		case ActionAddProperty:
+			if err := AddProperty(ctx, client, c.Class, findProperty(classes[c.Class], c.Property)); err != nil {
+				return err
+			}
+		case ActionChange:
+			if updates[c.Class] == nil {
+				updated = append(updated, c.Class)
+			}
+			updates[c.Class] = append(updates[c.Class], c.Field)
+		}
+	}
+	for _, className := range updated {
+		if err := updateFields(ctx, client, classes[className], updates[className]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateFields copies fields from want into the live class and updates it.
+func updateFields(ctx context.Context, client *weaviate.Client, want *models.Class, fields []string) error {
+	live, err := GetClass(ctx, client, want.Class)
+	if err != nil {
+		return err
+	}
+	w, err := genericMap(want)
+	if err != nil {
+		return err
+	}
+	h, err := genericMap(live)
+	if err != nil {
+		return err
+	}
+	for _, field := range fields {
+		setPath(h, field, lookup(w, field))
+	}
+	class, err := classFromMap(h)
+	if err != nil {
+		return err
+	}
+	return UpdateClass(ctx, client, class)
+}
+
+// updateSettings changes the settings of want.Class to the ones want lists.
+// It fails without changing anything if one of them cannot change in place.
+func updateSettings(ctx context.Context, client *weaviate.Client, want *models.Class) error {
+	live, err := GetClass(ctx, client, want.Class)
+	if err != nil {
+		return err
+	}
+	w, err := genericMap(want)
+	if err != nil {
+		return err
+	}
+	h, err := genericMap(live)
+	if err != nil {
+		return err
+	}
+	fields := subsetDiff("", w, h, classSkip)
+	for _, field := range fields {
+		if !mutable(field) {
+			return Errorf(KindValidation, "update class "+want.Class, "%s is %s, want %s; it cannot be changed in place",
+				field, encode(lookup(h, field)), encode(lookup(w, field)))
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return updateFields(ctx, client, want, fields)
+}
+
+func classFromMap(m map[string]interface{}) (*models.Class, error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	var class models.Class
+	return &class, json.Unmarshal(b, &class)
+}
+
+func setPath(m map[string]interface{}, path string, v interface{}) {
+	keys := strings.Split(path, ".")
+	for _, k := range keys[:len(keys)-1] {
+		next, ok := m[k].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			m[k] = next
+		}
+		m = next
+	}
+	m[keys[len(keys)-1]] = v
+}
+
+func findProperty(class *models.Class, name string) *models.Property {
+	for _, p := range class.Properties {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package academy
+
+import (
+	"reflect"
+	"testing"
+)
+
+// liveArticle is Article as the server returns it, with the defaults it
+// filled in.
+const liveArticle = `
+class: Article
+vectorizer: text2vec-contextionary
+invertedIndexConfig:
+  bm25: {b: 0.75, k1: 1.2}
+  cleanupIntervalSeconds: 60
+  stopwords: {preset: en}
+moduleConfig:
+  text2vec-contextionary: {vectorizeClassName: true}
+multiTenancyConfig: {enabled: false}
+replicationConfig: {factor: 1}
+shardingConfig: {desiredCount: 1, virtualPerPhysical: 128}
+vectorIndexType: hnsw
+vectorIndexConfig:
+  distance: cosine
+  ef: -1
+  efConstruction: 128
+  maxConnections: 64
+  pq: {enabled: false, segments: 0}
+properties:
+  - name: title
+    dataType: [text]
+    tokenization: word
+    indexFilterable: true
+    indexSearchable: true
+    moduleConfig:
+      text2vec-contextionary: {skip: false, vectorizePropertyName: false}
+  - name: url
+    dataType: [text]
+    tokenization: field
+    indexFilterable: true
+    indexSearchable: true
+`
+
+func TestDiffSchema(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		have string
+		// changes are the Change strings, each followed by (additive) or
+		// (reindex).
+		changes []string
+	}{
+		{
+			name: "server defaults",
+			want: `
+class: Article
+vectorizer: text2vec-contextionary
+properties:
+  - {name: title, dataType: [text]}
+  - {name: url, dataType: [text], tokenization: field}
+`,
+			have: liveArticle,
+		},
+		{
+			name: "defaults spelled out",
+			want: `
+class: Article
+invertedIndexConfig:
+  bm25: {b: 0.75, k1: 1.2}
+  stopwords: {preset: en}
+vectorIndexConfig: {distance: cosine, ef: -1}
+`,
+			have: liveArticle,
+		},
+		{
+			name:    "new class",
+			want:    `{class: Question}`,
+			have:    liveArticle,
+			changes: []string{"create-class Question (additive)"},
+		},
+		{
+			name: "settings and a new property",
+			want: `
+class: Article
+invertedIndexConfig:
+  bm25: {b: 0.8}
+  stopwords: {preset: none}
+vectorIndexConfig: {ef: 64, pq: {enabled: true}}
+properties:
+  - {name: title, dataType: [text]}
+  - {name: url, dataType: [text], tokenization: field}
+  - {name: body, dataType: [text]}
+`,
+			have: liveArticle,
+			changes: []string{
+				`change Article invertedIndexConfig.bm25.b: 0.75 -> 0.8 (additive)`,
+				`change Article invertedIndexConfig.stopwords.preset: "en" -> "none" (additive)`,
+				`change Article vectorIndexConfig.ef: -1 -> 64 (additive)`,
+				`change Article vectorIndexConfig.pq.enabled: false -> true (additive)`,
+				`add-property Article.body (additive)`,
+			},
+		},
+		{
+			name: "reindex required",
+			want: `
+class: Article
+vectorizer: text2vec-openai
+vectorIndexConfig: {distance: dot, efConstruction: 256}
+properties:
+  - {name: title, dataType: [text], tokenization: field}
+`,
+			have: liveArticle,
+			changes: []string{
+				`change Article vectorIndexConfig.distance: "cosine" -> "dot" (reindex)`,
+				`change Article vectorIndexConfig.efConstruction: 128 -> 256 (reindex)`,
+				`change Article vectorizer: "text2vec-contextionary" -> "text2vec-openai" (reindex)`,
+				`change Article.title tokenization: "word" -> "field" (reindex)`,
+				`remove-property Article.url (reindex)`,
+			},
+		},
+		{
+			name: "auto-schema keeps live properties",
+			want: `{class: Article, vectorizer: text2vec-contextionary}`,
+			have: liveArticle,
+		},
+		{
+			name: "nested moduleConfig",
+			want: `
+class: Article
+moduleConfig:
+  text2vec-contextionary: {vectorizeClassName: false}
+properties:
+  - name: title
+    dataType: [text]
+    moduleConfig:
+      text2vec-contextionary: {skip: true}
+  - {name: url, dataType: [text]}
+`,
+			have: liveArticle,
+			changes: []string{
+				`change Article moduleConfig.text2vec-contextionary.vectorizeClassName: true -> false (reindex)`,
+				`change Article.title moduleConfig.text2vec-contextionary.skip: false -> true (reindex)`,
+			},
+		},
+		{
+			name: "nested moduleConfig added",
+			want: `
+class: Article
+moduleConfig:
+  generative-openai: {model: gpt-4}
+`,
+			have: liveArticle,
+			changes: []string{
+				`change Article moduleConfig.generative-openai: (unset) -> {"model":"gpt-4"} (reindex)`,
+			},
+		},
+		{
+			name: "empty moduleConfig not configured",
+			want: `
+class: Article
+moduleConfig:
+  generative-openai: {}
+`,
+			have: liveArticle,
+		},
+		{
+			name: "empty moduleConfig with server settings",
+			want: `
+class: Article
+moduleConfig:
+  text2vec-contextionary: {}
+`,
+			have: liveArticle,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := ParseClasses([]byte(tt.want))
+			if err != nil {
+				t.Fatal(err)
+			}
+			have, err := ParseClasses([]byte(tt.have))
+			if err != nil {
+				t.Fatal(err)
+			}
+			diff, err := DiffSchema(want, have)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var changes []string
+			reindex := false
+			for _, c := range diff.Changes {
+				kind := " (additive)"
+				if !c.Additive {
+					kind = " (reindex)"
+					reindex = true
+				}
+				changes = append(changes, c.String()+kind)
+			}
+			if !reflect.DeepEqual(changes, tt.changes) {
+				t.Errorf("changes:\n%q\nwant:\n%q", changes, tt.changes)
+			}
+			if plan := diff.ReindexPlan(); (len(plan) > 0) != reindex {
+				t.Errorf("reindex plan %q for changes %q", plan, changes)
+			}
+		})
+	}
+}
+
+func TestMutable(t *testing.T) {
+	tests := []struct {
+		field string
+		want  bool
+	}{
+		{"invertedIndexConfig.bm25", true},
+		{"invertedIndexConfig.bm25.k1", true},
+		{"invertedIndexConfig.stopwords.additions", true},
+		{"invertedIndexConfig.indexTimestamps", false},
+		{"vectorIndexConfig.ef", true},
+		{"vectorIndexConfig.efConstruction", false},
+		{"vectorIndexConfig.pq.segments", true},
+		{"vectorizer", false},
+		{"moduleConfig.text2vec-openai.model", false},
+	}
+	for _, tt := range tests {
+		if got := mutable(tt.field); got != tt.want {
+			t.Errorf("mutable(%q) = %v, want %v", tt.field, got, tt.want)
+		}
+	}
+}
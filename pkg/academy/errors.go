@@ -0,0 +1,154 @@
+// Package academy holds the Weaviate operations behind the commands in cmd/:
+// schema management, batch imports, searches, aggregations and generative
+// queries. Operations return data and classified errors rather than
+// printing, so other programs can reuse them.
+package academy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/weaviate/weaviate-go-client/v4/weaviate/fault"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Kind classifies why an operation failed.
+type Kind int
+
+const (
+	KindUnknown Kind = iota
+	KindUnreachable
+	KindUnauthorized
+	KindClassNotFound
+	KindAlreadyExists
+	KindValidation
+	KindGraphQL
+	KindPartialBatch
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindUnreachable:
+		return "unreachable"
+	case KindUnauthorized:
+		return "unauthorized"
+	case KindClassNotFound:
+		return "class not found"
+	case KindAlreadyExists:
+		return "already exists"
+	case KindValidation:
+		return "validation"
+	case KindGraphQL:
+		return "graphql error"
+	case KindPartialBatch:
+		return "partial batch failure"
+	}
+	return "unknown"
+}
+
+// Error is a failed operation. Detail carries the raw server response, if
+// any, for debugging. Problems lists the errors found inside a GraphQL
+// response.
+type Error struct {
+	Kind     Kind
+	Op       string
+	Msg      string
+	Detail   string
+	Problems []Problem
+	Err      error
+}
+
+func (e *Error) Error() string {
+	if e.Op == "" {
+		return e.Msg
+	}
+	return e.Op + ": " + e.Msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Errorf returns an Error of the given kind with a formatted message.
+func Errorf(kind Kind, op, format string, args ...any) *Error {
+	return &Error{Kind: kind, Op: op, Msg: fmt.Sprintf(format, args...)}
+}
+
+// KindOf returns the kind of err, KindUnknown if it was never classified.
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return KindUnknown
+}
+
+// Classify wraps err, usually returned by the go client, in an Error whose
+// kind is derived from the HTTP status and transport failure. Errors that
+// are already classified are returned unchanged.
+func Classify(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return err
+	}
+
+	e = &Error{Kind: KindUnknown, Op: op, Msg: err.Error(), Err: err}
+	var ce *fault.WeaviateClientError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &ce) && !ce.IsUnexpectedStatusCode:
+		e.Kind = KindUnreachable
+		if ce.DerivedFromError != nil {
+			e.Msg = rootCause(ce.DerivedFromError).Error()
+		}
+	case errors.As(err, &ce):
+		e.Detail = ce.Msg
+		e.Msg = fmt.Sprintf("%s (status %d)", serverMessage(ce.Msg), ce.StatusCode)
+		switch {
+		case ce.StatusCode == http.StatusUnauthorized, ce.StatusCode == http.StatusForbidden:
+			e.Kind = KindUnauthorized
+		case ce.StatusCode == http.StatusNotFound:
+			e.Kind = KindClassNotFound
+		case strings.Contains(ce.Msg, "already exists"):
+			e.Kind = KindAlreadyExists
+		case ce.StatusCode == http.StatusUnprocessableEntity, ce.StatusCode == http.StatusBadRequest:
+			e.Kind = KindValidation
+		}
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr):
+		e.Kind = KindUnreachable
+	}
+	return e
+}
+
+// serverMessage extracts the messages from a Weaviate error response body.
+func serverMessage(body string) string {
+	var resp struct {
+		Error []struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(body), &resp); err != nil || len(resp.Error) == 0 {
+		return strings.TrimSpace(body)
+	}
+	msgs := make([]string, len(resp.Error))
+	for i, m := range resp.Error {
+		msgs[i] = m.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func rootCause(err error) error {
+	for {
+		next := errors.Unwrap(err)
+		if next == nil {
+			return err
+		}
+		err = next
+	}
+}
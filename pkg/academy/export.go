@@ -0,0 +1,142 @@
+package academy
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"github.com/weaviate/weaviate-go-client/v4/weaviate"
+	"github.com/weaviate/weaviate/entities/models"
+	"io"
+)
+
+// ScanObjects calls fn for every object of className in ID order, fetching
+// pageSize objects per request with the cursor API. Vectors are included if
+// withVector is set.
+func ScanObjects(ctx context.Context, client *weaviate.Client, className string, pageSize int, withVector bool, fn func(*models.Object) error) error {
+	op := "read objects of " + className
+	if pageSize <= 0 {
+		return Errorf(KindValidation, op, "page size must be at least 1, got %d", pageSize)
+	}
+	after := ""
+	for {
+		getter := client.Data().ObjectsGetter().
+			WithClassName(className).
+			WithLimit(pageSize)
+		if after != "" {
+			getter = getter.WithAfter(after)
+		}
+		if withVector {
+			getter = getter.WithVector()
+		}
+		objects, err := getter.Do(ctx)
+		if err != nil {
+			return Classify(op, err)
+		}
+		for _, o := range objects {
+			if err := fn(o); err != nil {
+				return err
+			}
+		}
+		if len(objects) == 0 || len(objects) < pageSize {
+			return nil
+		}
+		after = objects[len(objects)-1].ID.String()
+	}
+}
+
+// ExportClass writes the definition of className followed by all of its
+// objects, with vectors, to w as JSON lines. It returns the number of
+// objects written.
+func ExportClass(ctx context.Context, client *weaviate.Client, className string, w io.Writer) (int, error) {
+	class, err := GetClass(ctx, client, className)
+	if err != nil {
+		return 0, err
+	}
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(class); err != nil {
+		return 0, err
+	}
+	n := 0
+	err = ScanObjects(ctx, client, className, 100, true, func(o *models.Object) error {
+		n++
+		return enc.Encode(o)
+	})
+	return n, err
+}
+
+// RestoreResult reports RestoreClass. Failed indexes count objects in file
+// order.
+type RestoreResult struct {
+	Class    string         `json:"class"`
+	Restored int            `json:"restored"`
+	Failed   []BatchFailure `json:"failed,omitempty"`
+}
+
+// RestoreClass creates the class written by ExportClass and imports its
+// objects with their original IDs and vectors, batchSize at a time. Objects
+// the server rejects are collected in the result, which is returned together
+// with a KindPartialBatch error.
+func RestoreClass(ctx context.Context, client *weaviate.Client, r io.Reader, batchSize int) (*RestoreResult, error) {
+	if batchSize <= 0 {
+		return nil, Errorf(KindValidation, "restore", "batch size must be at least 1, got %d", batchSize)
+	}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 1024*1024), 64*1024*1024)
+	if !sc.Scan() {
+		if err := sc.Err(); err != nil {
+			return nil, err
+		}
+		return nil, Errorf(KindValidation, "restore", "empty export")
+	}
+	var class models.Class
+	if err := json.Unmarshal(sc.Bytes(), &class); err != nil {
+		return nil, Errorf(KindValidation, "restore", "class definition: %v", err)
+	}
+	if err := CreateClass(ctx, client, &class); err != nil {
+		return nil, err
+	}
+
+	result := &RestoreResult{Class: class.Class}
+	read := 0
+	var batch []*models.Object
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		res, err := ImportObjects(ctx, client, batch)
+		if res == nil {
+			return err
+		}
+		offset := result.Restored + len(result.Failed)
+		for _, f := range res.Failed {
+			f.Index += offset
+			result.Failed = append(result.Failed, f)
+		}
+		result.Restored += len(batch) - len(res.Failed)
+		batch = batch[:0]
+		return nil
+	}
+	for sc.Scan() {
+		var o models.Object
+		if err := json.Unmarshal(sc.Bytes(), &o); err != nil {
+			return result, Errorf(KindValidation, "restore", "object %d: %v", read, err)
+		}
+		read++
+		batch = append(batch, &o)
+		if len(batch) == batchSize {
+			if err := flush(); err != nil {
+				return result, err
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return result, err
+	}
+	if err := flush(); err != nil {
+		return result, err
+	}
+	if len(result.Failed) > 0 {
+		return result, Errorf(KindPartialBatch, "restore "+class.Class, "%d of %d objects failed", len(result.Failed), read)
+	}
+	return result, nil
+}
@@ -0,0 +1,98 @@
+package academy
+
+import (
+	"context"
+	"crypto/md5"
+	"fmt"
+	"github.com/go-openapi/strfmt"
+	"github.com/weaviate/weaviate-go-client/v4/weaviate"
+	"github.com/weaviate/weaviate/entities/models"
+	"io"
+	"strings"
+)
+
+// ObjectID derives a deterministic UUID from input, so importing the same
+// data twice updates objects instead of duplicating them.
+func ObjectID(input string) strfmt.UUID {
+	input = strings.ToLower(input)
+	hash := md5.Sum([]byte(input))
+	u := fmt.Sprintf("%x-%x-%x-%x-%x", hash[0:4], hash[4:6], hash[6:8], hash[8:10], hash[10:])
+	return strfmt.UUID(u)
+}
+
+// ImportJeopardy streams the JSON array of Jeopardy questions read from r
+// into im as objects of className, starting after byte offset as
+// StreamArrayFrom does. Decoding stops with ctx's error once ctx is done; the
+// caller closes im, which still sends what was added.
+func ImportJeopardy(ctx context.Context, im *Importer, className string, r io.Reader, offset int64) error {
+	return StreamArrayFrom(r, offset, func(q jeopardyRecord, end int64) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return im.AddAt(jeopardyObject(className, q), end)
+	})
+}
+
+// jeopardyRecord is an element of a Jeopardy JSON file such as
+// jeopardy_100.json, whose keys are capitalised: "Round", "Value".
+type jeopardyRecord struct {
+	Round    string `json:"Round"`
+	Value    int    `json:"Value"`
+	Question string `json:"Question"`
+	Answer   string `json:"Answer"`
+}
+
+// jeopardyObject converts q into an object of className with an ID derived
+// from the question text.
+func jeopardyObject(className string, q jeopardyRecord) *models.Object {
+	return &models.Object{
+		Class: className,
+		Properties: map[string]interface{}{
+			"round":    q.Round,
+			"value":    q.Value,
+			"question": q.Question,
+			"answer":   q.Answer,
+		},
+		ID: ObjectID(q.Question),
+	}
+}
+
+// BatchFailure is an object the server rejected during a batch import.
+type BatchFailure struct {
+	Index    int         `json:"index"`
+	ID       strfmt.UUID `json:"id,omitempty"`
+	Messages []string    `json:"messages"`
+}
+
+// BatchResult is the outcome of a batch import.
+type BatchResult struct {
+	Objects []models.ObjectsGetResponse `json:"objects"`
+	Failed  []BatchFailure              `json:"failed,omitempty"`
+}
+
+// ImportObjects sends objects in a single batch. If some objects are
+// rejected the result is returned together with a KindPartialBatch error.
+func ImportObjects(ctx context.Context, client *weaviate.Client, objects []*models.Object) (*BatchResult, error) {
+	res, err := client.Batch().ObjectsBatcher().
+		WithObjects(objects...).
+		Do(ctx)
+	if err != nil {
+		return nil, Classify("batch import", err)
+	}
+
+	result := &BatchResult{Objects: res}
+	for i, r := range res {
+		if r.Result == nil || r.Result.Errors == nil {
+			continue
+		}
+		failure := BatchFailure{Index: i, ID: r.ID}
+		for _, e := range r.Result.Errors.Error {
+			failure.Messages = append(failure.Messages, e.Message)
+		}
+		result.Failed = append(result.Failed, failure)
+	}
+	if len(result.Failed) > 0 {
+		return result, Errorf(KindPartialBatch, "batch import", "%d of %d objects failed", len(result.Failed), len(res))
+	}
+	return result, nil
+}
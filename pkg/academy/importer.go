@@ -0,0 +1,395 @@
+package academy
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"github.com/weaviate/weaviate-go-client/v4/weaviate"
+	"github.com/weaviate/weaviate/entities/models"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// StreamArray decodes the JSON array read from r one element at a time and
+// calls fn with each, so memory use does not grow with the array.
+func StreamArray[T any](r io.Reader, fn func(T) error) error {
+	return StreamArrayFrom(r, 0, func(v T, _ int64) error { return fn(v) })
+}
+
+// StreamArrayFrom is StreamArray for an array whose elements up to byte
+// offset were read before, e.g. as recorded by a Checkpoint; r must be
+// positioned at offset. fn is called with each element and the byte offset
+// just past it.
+func StreamArrayFrom[T any](r io.Reader, offset int64, fn func(T, int64) error) error {
+	const op = "decode array"
+	base := offset
+	if offset > 0 {
+		// Resume as if the array started here: drop the separator in front
+		// of the next element and decode "[" followed by the rest.
+		br := bufio.NewReader(r)
+		for {
+			c, err := br.ReadByte()
+			if err != nil {
+				return Errorf(KindValidation, op, "offset %d: %v", offset, err)
+			}
+			base++
+			if c == ' ' || c == '\t' || c == '\n' || c == '\r' {
+				continue
+			}
+			if c == ',' {
+				break
+			}
+			if c != ']' {
+				return Errorf(KindValidation, op, "offset %d is not the end of an element", offset)
+			}
+			base--
+			if err := br.UnreadByte(); err != nil {
+				return err
+			}
+			break
+		}
+		// The "[" is not part of the input.
+		base--
+		r = io.MultiReader(strings.NewReader("["), br)
+	}
+
+	dec := json.NewDecoder(r)
+	tok, err := dec.Token()
+	if err != nil {
+		return Errorf(KindValidation, op, "%v", err)
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return Errorf(KindValidation, op, "expected a JSON array, got %v", tok)
+	}
+	for i := 0; dec.More(); i++ {
+		var v T
+		if err := dec.Decode(&v); err != nil {
+			return Errorf(KindValidation, op, "element %d at byte %d: %v", i, base+dec.InputOffset(), err)
+		}
+		if err := fn(v, base+dec.InputOffset()); err != nil {
+			return err
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return Errorf(KindValidation, op, "%v", err)
+	}
+	return nil
+}
+
+// ImportOptions tune an Importer.
+type ImportOptions struct {
+	// BatchSize is the number of objects per request, default 100.
+	BatchSize int
+	// Workers is the number of batches sent at the same time, default 1.
+	Workers int
+	// QueueSize is the number of full batches that may wait for a worker,
+	// default Workers. Add blocks while the queue is full.
+	QueueSize int
+	// Start is the index of the first object added, e.g. when resuming an
+	// import from a Checkpoint; Failed indexes and Acknowledged count from
+	// there.
+	Start int
+	// Backoff retries batches that fail with a Transient error and objects
+	// rejected for a transient reason such as a vectorizer timeout.
+	Backoff Backoff
+	// DeadLetter, if set, receives a DeadLetter line for every object that
+	// is finally rejected.
+	DeadLetter io.Writer
+	// Progress, if set, is called after batches complete, one call at a
+	// time and never with older stats than the call before. Batches that
+	// complete during a call may be reported together.
+	Progress func(ImportStats)
+}
+
+// ImportStats counts what an Importer has done. Retried counts objects sent
+// again; Failed indexes count objects in the order they were added.
+// Acknowledged is the index up to which every batch has completed, imported
+// or dead-lettered, and Position the position given to AddAt for the object
+// before it: an import restarted from there loses nothing.
+type ImportStats struct {
+	Added        int            `json:"added"`
+	Imported     int            `json:"imported"`
+	Batches      int            `json:"batches"`
+	Retried      int            `json:"retried"`
+	Acknowledged int            `json:"acknowledged"`
+	Position     int64          `json:"position,omitempty"`
+	Failed       []BatchFailure `json:"failed,omitempty"`
+}
+
+// batch is a slice of the added objects; offset is the index of its first
+// object and end the position of its last.
+type batch struct {
+	offset  int
+	end     int64
+	objects []*models.Object
+}
+
+// Importer sends objects in batches as they are added, using up to Workers
+// concurrent requests and holding at most QueueSize+Workers+1 batches in
+// memory. Objects the server rejects are collected in the stats and the
+// dead-letter file; any other error that outlasts the retries stops the
+// import and is returned by the next Add and by Close.
+type Importer struct {
+	ctx     context.Context
+	write   batchWriter
+	opts    ImportOptions
+	pending batch
+	queue   chan batch
+	wg      sync.WaitGroup
+
+	mu    sync.Mutex
+	stats ImportStats
+	err   error
+	// done holds the completed batches past stats.Acknowledged by offset.
+	done map[int]batch
+	// snapshots counts the stats taken for Progress.
+	snapshots int
+
+	// progressMu serializes the Progress calls without holding mu, so Add
+	// and the workers keep updating the stats during a call; reported is
+	// the snapshot it was last called with.
+	progressMu sync.Mutex
+	reported   int
+}
+
+// batchWriter sends objects in one batch request, like ImportObjects.
+type batchWriter func(ctx context.Context, objects []*models.Object) (*BatchResult, error)
+
+// NewImporter starts the workers of an Importer writing to client. ctx
+// governs the requests: cancelling it aborts the batches in flight. To stop
+// early but keep what was added, stop calling Add and call Close.
+func NewImporter(ctx context.Context, client *weaviate.Client, opts ImportOptions) *Importer {
+	return newImporter(ctx, func(ctx context.Context, objects []*models.Object) (*BatchResult, error) {
+		return ImportObjects(ctx, client, objects)
+	}, opts)
+}
+
+func newImporter(ctx context.Context, write batchWriter, opts ImportOptions) *Importer {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = 1
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = opts.Workers
+	}
+	im := &Importer{
+		ctx:   ctx,
+		write: write,
+		opts:  opts,
+		queue: make(chan batch, opts.QueueSize),
+		done:  map[int]batch{},
+	}
+	im.pending.offset = opts.Start
+	im.stats.Acknowledged = opts.Start
+	im.wg.Add(opts.Workers)
+	for i := 0; i < opts.Workers; i++ {
+		go im.work()
+	}
+	return im
+}
+
+// Add queues obj and hands the batch to the workers once it is full,
+// blocking while the queue is full. Add and Close are called from one
+// goroutine.
+func (im *Importer) Add(obj *models.Object) error {
+	return im.AddAt(obj, 0)
+}
+
+// AddAt is Add for an object ending at pos in the input, reported back as
+// ImportStats.Position once its batch and all before it completed.
+func (im *Importer) AddAt(obj *models.Object, pos int64) error {
+	if err := im.failure(); err != nil {
+		return err
+	}
+	im.mu.Lock()
+	im.stats.Added++
+	im.mu.Unlock()
+	if im.pending.objects == nil {
+		im.pending.objects = make([]*models.Object, 0, im.opts.BatchSize)
+	}
+	im.pending.objects = append(im.pending.objects, obj)
+	im.pending.end = pos
+	if len(im.pending.objects) == im.opts.BatchSize {
+		im.enqueue()
+	}
+	return nil
+}
+
+func (im *Importer) enqueue() {
+	if len(im.pending.objects) == 0 {
+		return
+	}
+	n := len(im.pending.objects)
+	im.queue <- im.pending
+	im.pending = batch{offset: im.pending.offset + n}
+}
+
+func (im *Importer) failure() error {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	return im.err
+}
+
+func (im *Importer) work() {
+	defer im.wg.Done()
+	for b := range im.queue {
+		// After a failure the remaining batches are drained, not sent.
+		if im.failure() != nil {
+			continue
+		}
+		err := im.send(b)
+
+		var snapshot int
+		var stats ImportStats
+		im.mu.Lock()
+		switch {
+		case err != nil && im.err == nil:
+			im.err = err
+		case err == nil:
+			im.stats.Batches++
+			im.acknowledge(b)
+			if im.opts.Progress != nil {
+				im.snapshots++
+				snapshot, stats = im.snapshots, im.stats
+				stats.Failed = append([]BatchFailure(nil), im.stats.Failed...)
+			}
+		}
+		im.mu.Unlock()
+		if snapshot > 0 {
+			im.progress(snapshot, stats)
+		}
+	}
+}
+
+// progress calls Progress with stats unless a later snapshot was reported
+// already, so Acknowledged never goes back.
+func (im *Importer) progress(snapshot int, stats ImportStats) {
+	im.progressMu.Lock()
+	defer im.progressMu.Unlock()
+	if snapshot < im.reported {
+		return
+	}
+	im.reported = snapshot
+	im.opts.Progress(stats)
+}
+
+// acknowledge records b as completed and advances stats.Acknowledged past
+// the batches completed without a gap.
+func (im *Importer) acknowledge(b batch) {
+	im.done[b.offset] = b
+	for {
+		next, ok := im.done[im.stats.Acknowledged]
+		if !ok {
+			return
+		}
+		delete(im.done, next.offset)
+		im.stats.Acknowledged += len(next.objects)
+		im.stats.Position = next.end
+	}
+}
+
+// send imports b, retrying the whole batch after a transient error and the
+// objects rejected for a transient reason, and rejects the others.
+func (im *Importer) send(b batch) error {
+	objects := b.objects
+	indexes := make([]int, len(objects))
+	for i := range indexes {
+		indexes[i] = b.offset + i
+	}
+	for attempt := 1; ; attempt++ {
+		retry := attempt <= im.opts.Backoff.Retries
+		res, err := im.write(im.ctx, objects)
+		if res == nil {
+			if !retry || !Transient(err) {
+				return err
+			}
+			if !im.opts.Backoff.wait(im.ctx, attempt) {
+				return im.ctx.Err()
+			}
+			im.count(0, len(objects))
+			continue
+		}
+
+		var again []*models.Object
+		var againIndexes []int
+		var failures []BatchFailure
+		for _, f := range res.Failed {
+			if retry && transientFailure(f) {
+				again = append(again, objects[f.Index])
+				againIndexes = append(againIndexes, indexes[f.Index])
+				failures = append(failures, f)
+				continue
+			}
+			if err := im.reject(f, objects[f.Index], indexes[f.Index], attempt); err != nil {
+				return err
+			}
+		}
+		im.count(len(objects)-len(res.Failed), len(again))
+		if len(again) == 0 {
+			return nil
+		}
+		if !im.opts.Backoff.wait(im.ctx, attempt) {
+			// Aborted: what was waiting for a retry is rejected as is.
+			for i, f := range failures {
+				if err := im.reject(f, again[i], againIndexes[i], attempt); err != nil {
+					return err
+				}
+			}
+			return im.ctx.Err()
+		}
+		objects, indexes = again, againIndexes
+	}
+}
+
+func (im *Importer) count(imported, retried int) {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	im.stats.Imported += imported
+	im.stats.Retried += retried
+}
+
+// reject records obj, the index-th object added, as finally failed with the
+// messages of f.
+func (im *Importer) reject(f BatchFailure, obj *models.Object, index, attempts int) error {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	f.Index = index
+	im.stats.Failed = append(im.stats.Failed, f)
+	if im.opts.DeadLetter == nil {
+		return nil
+	}
+	line, err := json.Marshal(DeadLetter{Index: index, Messages: f.Messages, Attempts: attempts, Object: obj})
+	if err != nil {
+		return err
+	}
+	if _, err := im.opts.DeadLetter.Write(append(line, '\n')); err != nil {
+		return Errorf(KindUnknown, "write dead letter", "%v", err)
+	}
+	return nil
+}
+
+// Close sends the remaining objects, waits for the workers and returns the
+// stats, together with the error that stopped the import or a
+// KindPartialBatch error if any object was rejected.
+func (im *Importer) Close() (*ImportStats, error) {
+	if im.failure() == nil {
+		im.enqueue()
+	}
+	close(im.queue)
+	im.wg.Wait()
+
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	sort.Slice(im.stats.Failed, func(i, j int) bool { return im.stats.Failed[i].Index < im.stats.Failed[j].Index })
+	if im.err != nil {
+		return &im.stats, im.err
+	}
+	if len(im.stats.Failed) > 0 {
+		return &im.stats, Errorf(KindPartialBatch, "import", "%d of %d objects failed", len(im.stats.Failed), im.stats.Added)
+	}
+	return &im.stats, nil
+}
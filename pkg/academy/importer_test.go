@@ -0,0 +1,612 @@
+package academy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/go-openapi/strfmt"
+	"github.com/weaviate/weaviate/entities/models"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStreamArray(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []int
+		ends  []int64
+		err   bool
+	}{
+		{name: "empty", input: `[]`},
+		{name: "one", input: `[7]`, want: []int{7}, ends: []int64{2}},
+		{name: "spaced", input: " [10, 200 ,\n3000 ]\n", want: []int{10, 200, 3000}, ends: []int64{4, 9, 16}},
+		{name: "not an array", input: `{"a": 1}`, err: true},
+		{name: "not json", input: `nope`, err: true},
+		{name: "bad element", input: `[1, "x", 3]`, want: []int{1}, ends: []int64{2}, err: true},
+		{name: "unterminated", input: `[1, 2`, want: []int{1, 2}, ends: []int64{2, 5}, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			var ends []int64
+			err := StreamArrayFrom(strings.NewReader(tt.input), 0, func(v int, end int64) error {
+				got = append(got, v)
+				ends = append(ends, end)
+				return nil
+			})
+			if (err != nil) != tt.err {
+				t.Fatalf("err = %v, want error %v", err, tt.err)
+			}
+			if err != nil && KindOf(err) != KindValidation {
+				t.Errorf("kind = %v, want %v", KindOf(err), KindValidation)
+			}
+			if !reflect.DeepEqual(got, tt.want) || !reflect.DeepEqual(ends, tt.ends) {
+				t.Errorf("got %v ending at %v, want %v ending at %v", got, ends, tt.want, tt.ends)
+			}
+			for i, end := range ends {
+				if !strings.HasSuffix(tt.input[:end], fmt.Sprint(got[i])) {
+					t.Errorf("element %d ends at %d, input there is %q", i, end, tt.input[:end])
+				}
+			}
+		})
+	}
+}
+
+func TestStreamArrayStopsOnError(t *testing.T) {
+	stop := fmt.Errorf("stop")
+	var got []int
+	err := StreamArray(strings.NewReader(`[1, 2, 3]`), func(v int) error {
+		got = append(got, v)
+		if v == 2 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Errorf("err = %v, want %v", err, stop)
+	}
+	if !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Errorf("got %v, want [1 2]", got)
+	}
+}
+
+// fakeWriter is a batchWriter that records the objects it receives instead of
+// sending them. delay, if set, is how long a batch takes. fail, if set,
+// returns the error of the whole batch on its attempt-th send, counted for
+// its first object, and reject the messages an object is rejected with on its
+// attempt-th send; nil accepts it.
+type fakeWriter struct {
+	delay  func(objects []*models.Object) time.Duration
+	fail   func(objects []*models.Object, attempt int) error
+	reject func(obj *models.Object, attempt int) []string
+
+	mu       sync.Mutex
+	batches  [][]*models.Object
+	attempts map[strfmt.UUID]int
+	written  map[strfmt.UUID]int
+}
+
+func (w *fakeWriter) write(ctx context.Context, objects []*models.Object) (*BatchResult, error) {
+	if w.delay != nil {
+		time.Sleep(w.delay(objects))
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.written == nil {
+		w.attempts = map[strfmt.UUID]int{}
+		w.written = map[strfmt.UUID]int{}
+	}
+	for _, obj := range objects {
+		w.attempts[obj.ID]++
+	}
+	if w.fail != nil {
+		if err := w.fail(objects, w.attempts[objects[0].ID]); err != nil {
+			return nil, err
+		}
+	}
+	// Keep a copy, the caller may reuse objects.
+	w.batches = append(w.batches, append([]*models.Object(nil), objects...))
+	res := &BatchResult{}
+	for i, obj := range objects {
+		res.Objects = append(res.Objects, models.ObjectsGetResponse{Object: *obj})
+		if w.reject != nil {
+			if msgs := w.reject(obj, w.attempts[obj.ID]); msgs != nil {
+				res.Failed = append(res.Failed, BatchFailure{Index: i, ID: obj.ID, Messages: msgs})
+				continue
+			}
+		}
+		w.written[obj.ID]++
+	}
+	if len(res.Failed) > 0 {
+		return res, Errorf(KindPartialBatch, "batch import", "%d of %d objects failed", len(res.Failed), len(objects))
+	}
+	return res, nil
+}
+
+// testObjects returns n objects numbered by their property n.
+func testObjects(n int) []*models.Object {
+	objects := make([]*models.Object, n)
+	for i := range objects {
+		objects[i] = &models.Object{
+			Class:      "Test",
+			ID:         ObjectID(fmt.Sprint(i)),
+			Properties: map[string]interface{}{"n": i},
+		}
+	}
+	return objects
+}
+
+// number returns the property n of obj.
+func number(obj *models.Object) int {
+	return obj.Properties.(map[string]interface{})["n"].(int)
+}
+
+// rejectNumbers rejects the objects numbered ns.
+func rejectNumbers(ns ...int) func(*models.Object, int) []string {
+	return func(obj *models.Object, _ int) []string {
+		for _, n := range ns {
+			if number(obj) == n {
+				return []string{"invalid object"}
+			}
+		}
+		return nil
+	}
+}
+
+// slowFirst makes earlier batches take longer, so that with several workers
+// they complete out of order.
+func slowFirst(objects []*models.Object) time.Duration {
+	return time.Duration(20-number(objects[0])%20) * time.Millisecond
+}
+
+func TestImporterBatches(t *testing.T) {
+	tests := []struct {
+		name      string
+		objects   int
+		batchSize int
+		workers   int
+		queueSize int
+		reject    []int
+		batches   int
+		failed    []int
+	}{
+		{name: "nothing", objects: 0, batchSize: 3},
+		{name: "partial last batch", objects: 10, batchSize: 3, batches: 4},
+		{name: "full batches", objects: 10, batchSize: 5, batches: 2},
+		{name: "default size", objects: 150, batches: 2},
+		{name: "rejected", objects: 10, batchSize: 3, reject: []int{1, 4, 9}, batches: 4, failed: []int{1, 4, 9}},
+		{name: "workers", objects: 10, batchSize: 3, workers: 4, batches: 4},
+		{name: "more workers than batches", objects: 7, batchSize: 3, workers: 8, batches: 3},
+		{name: "short queue", objects: 25, batchSize: 2, workers: 4, queueSize: 1, batches: 13},
+		{name: "rejected by workers", objects: 25, batchSize: 2, workers: 4, reject: []int{0, 13, 24}, batches: 13, failed: []int{0, 13, 24}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &fakeWriter{delay: slowFirst, reject: rejectNumbers(tt.reject...)}
+			im := newImporter(context.Background(), w.write, ImportOptions{
+				BatchSize: tt.batchSize,
+				Workers:   tt.workers,
+				QueueSize: tt.queueSize,
+			})
+			objects := testObjects(tt.objects)
+			for _, obj := range objects {
+				if err := im.Add(obj); err != nil {
+					t.Fatal(err)
+				}
+			}
+			stats, err := im.Close()
+			if (err != nil) != (len(tt.failed) > 0) || (err != nil && KindOf(err) != KindPartialBatch) {
+				t.Fatalf("err = %v", err)
+			}
+			imported := tt.objects - len(tt.failed)
+			if stats.Added != tt.objects || stats.Imported != imported || stats.Batches != tt.batches {
+				t.Errorf("added %d, imported %d in %d batches, want %d, %d in %d",
+					stats.Added, stats.Imported, stats.Batches, tt.objects, imported, tt.batches)
+			}
+			var failed []int
+			for _, f := range stats.Failed {
+				failed = append(failed, f.Index)
+			}
+			if !reflect.DeepEqual(failed, tt.failed) {
+				t.Errorf("failed %v, want %v", failed, tt.failed)
+			}
+			if len(w.batches) != tt.batches {
+				t.Errorf("wrote %d batches, want %d", len(w.batches), tt.batches)
+			}
+			for _, obj := range objects {
+				if n := w.written[obj.ID]; n != 1 && !contains(tt.failed, number(obj)) {
+					t.Errorf("object %d written %d times", number(obj), n)
+				}
+			}
+		})
+	}
+}
+
+func TestTransientFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []string
+		want     bool
+	}{
+		{name: "no messages", want: false},
+		{name: "timeout", messages: []string{"vectorize: context deadline exceeded"}, want: true},
+		{name: "rate limit", messages: []string{"OpenAI API: Rate limit reached for requests"}, want: true},
+		{name: "upstream 5xx", messages: []string{"connection to: OpenAI API failed with status code: 503"}, want: true},
+		{name: "case insensitive", messages: []string{"Post: Unexpected EOF"}, want: true},
+		{name: "invalid object", messages: []string{"invalid text property 'value' on class 'JeopardyQuestion'"}, want: false},
+		{name: "all transient", messages: []string{"request timed out", "429 Too Many Requests"}, want: true},
+		{name: "one permanent", messages: []string{"request timed out", "no such prop with name 'points'"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := transientFailure(BatchFailure{Messages: tt.messages}); got != tt.want {
+				t.Errorf("transientFailure(%q) = %v, want %v", tt.messages, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackoffDelay(t *testing.T) {
+	tests := []struct {
+		name    string
+		backoff Backoff
+		attempt int
+		want    time.Duration
+	}{
+		{name: "default first", attempt: 1, want: 500 * time.Millisecond},
+		{name: "default doubled", attempt: 3, want: 2 * time.Second},
+		{name: "default capped", attempt: 7, want: 30 * time.Second},
+		{name: "first", backoff: Backoff{Initial: 100 * time.Millisecond, Max: time.Second}, attempt: 1, want: 100 * time.Millisecond},
+		{name: "second", backoff: Backoff{Initial: 100 * time.Millisecond, Max: time.Second}, attempt: 2, want: 200 * time.Millisecond},
+		{name: "below max", backoff: Backoff{Initial: 100 * time.Millisecond, Max: time.Second}, attempt: 4, want: 800 * time.Millisecond},
+		{name: "at max", backoff: Backoff{Initial: 100 * time.Millisecond, Max: time.Second}, attempt: 5, want: time.Second},
+		{name: "long after max", backoff: Backoff{Initial: 100 * time.Millisecond, Max: time.Second}, attempt: 100, want: time.Second},
+		{name: "initial above max", backoff: Backoff{Initial: time.Minute, Max: time.Second}, attempt: 1, want: time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The jitter takes off up to a quarter.
+			for i := 0; i < 100; i++ {
+				got := tt.backoff.delay(tt.attempt)
+				if got > tt.want || got < tt.want-tt.want/4 {
+					t.Fatalf("delay(%d) = %v, want %v less up to a quarter", tt.attempt, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestImporterRetries(t *testing.T) {
+	const timeout = "vectorize: context deadline exceeded"
+	// rejectUntil rejects the objects numbered ns with msg until their
+	// attempt-th send.
+	rejectUntil := func(attempt int, msg string, ns ...int) func(*models.Object, int) []string {
+		return func(obj *models.Object, a int) []string {
+			for _, n := range ns {
+				if number(obj) == n && a < attempt {
+					return []string{msg}
+				}
+			}
+			return nil
+		}
+	}
+	tests := []struct {
+		name     string
+		retries  int
+		fail     func([]*models.Object, int) error
+		reject   func(*models.Object, int) []string
+		imported int
+		retried  int
+		failed   []int
+		attempts []int
+		err      Kind
+	}{
+		{
+			name:     "accepted",
+			retries:  3,
+			imported: 10,
+		},
+		{
+			name:     "transient objects retried",
+			retries:  3,
+			reject:   rejectUntil(3, timeout, 2, 5),
+			imported: 10,
+			retried:  4,
+		},
+		{
+			name:     "retries exhausted",
+			retries:  2,
+			reject:   rejectUntil(100, timeout, 2, 5),
+			imported: 8,
+			retried:  4,
+			failed:   []int{2, 5},
+			attempts: []int{3, 3},
+			err:      KindPartialBatch,
+		},
+		{
+			name:     "permanent failure not retried",
+			retries:  3,
+			reject:   rejectUntil(100, "no such prop with name 'points'", 4),
+			imported: 9,
+			failed:   []int{4},
+			attempts: []int{1},
+			err:      KindPartialBatch,
+		},
+		{
+			name:     "no retries",
+			reject:   rejectUntil(2, timeout, 7),
+			imported: 9,
+			failed:   []int{7},
+			attempts: []int{1},
+			err:      KindPartialBatch,
+		},
+		{
+			name:    "transient batch error retried",
+			retries: 1,
+			fail: func(objects []*models.Object, attempt int) error {
+				if number(objects[0]) == 3 && attempt == 1 {
+					return Errorf(KindUnreachable, "batch import", "connection refused")
+				}
+				return nil
+			},
+			imported: 10,
+			retried:  3,
+		},
+		{
+			name:    "permanent batch error stops",
+			retries: 3,
+			fail: func(objects []*models.Object, attempt int) error {
+				if number(objects[0]) == 3 {
+					return Errorf(KindValidation, "batch import", "class Test not found")
+				}
+				return nil
+			},
+			imported: 3,
+			err:      KindValidation,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &fakeWriter{fail: tt.fail, reject: tt.reject}
+			var dl bytes.Buffer
+			im := newImporter(context.Background(), w.write, ImportOptions{
+				BatchSize:  3,
+				Backoff:    Backoff{Retries: tt.retries, Initial: time.Millisecond},
+				DeadLetter: &dl,
+			})
+			for _, obj := range testObjects(10) {
+				if err := im.Add(obj); err != nil {
+					break
+				}
+			}
+			stats, err := im.Close()
+			if tt.err == KindUnknown && err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if tt.err != KindUnknown && KindOf(err) != tt.err {
+				t.Fatalf("err = %v, want kind %v", err, tt.err)
+			}
+			if stats.Imported != tt.imported || stats.Retried != tt.retried {
+				t.Errorf("imported %d, retried %d, want %d and %d", stats.Imported, stats.Retried, tt.imported, tt.retried)
+			}
+			var failed []int
+			for _, f := range stats.Failed {
+				failed = append(failed, f.Index)
+			}
+			if !reflect.DeepEqual(failed, tt.failed) {
+				t.Errorf("failed %v, want %v", failed, tt.failed)
+			}
+			var dead, attempts []int
+			err = ReadDeadLetters(&dl, func(d DeadLetter) error {
+				if want := ObjectID(fmt.Sprint(d.Index)); d.Object.ID != want {
+					t.Errorf("dead letter %d holds object %s, want %s", d.Index, d.Object.ID, want)
+				}
+				dead = append(dead, d.Index)
+				attempts = append(attempts, d.Attempts)
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(dead, tt.failed) || !reflect.DeepEqual(attempts, tt.attempts) {
+				t.Errorf("dead letters %v after %v attempts, want %v after %v", dead, attempts, tt.failed, tt.attempts)
+			}
+		})
+	}
+}
+
+func contains(ns []int, n int) bool {
+	for _, m := range ns {
+		if m == n {
+			return true
+		}
+	}
+	return false
+}
+
+func TestStreamArrayFromOffset(t *testing.T) {
+	inputs := []string{
+		`[1,2,3]`,
+		" [10, 200 ,\n3000 ]\n",
+		"[\n  {\"n\": 1},\n  {\"n\": 22}\n]",
+	}
+	for _, input := range inputs {
+		var all []json.RawMessage
+		var ends []int64
+		err := StreamArrayFrom(strings.NewReader(input), 0, func(v json.RawMessage, end int64) error {
+			all = append(all, v)
+			ends = append(ends, end)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Resuming after every element yields the rest at the same offsets.
+		for i, offset := range ends {
+			var rest []json.RawMessage
+			var restEnds []int64
+			err := StreamArrayFrom(strings.NewReader(input[offset:]), offset, func(v json.RawMessage, end int64) error {
+				rest = append(rest, v)
+				restEnds = append(restEnds, end)
+				return nil
+			})
+			if err != nil {
+				t.Errorf("%q from %d: %v", input, offset, err)
+				continue
+			}
+			if fmt.Sprint(rest) != fmt.Sprint(all[i+1:]) || fmt.Sprint(restEnds) != fmt.Sprint(ends[i+1:]) {
+				t.Errorf("%q from %d: got %s ending at %v, want %s ending at %v",
+					input, offset, rest, restEnds, all[i+1:], ends[i+1:])
+			}
+		}
+	}
+}
+
+func TestStreamArrayFromBadOffset(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		offset int64
+	}{
+		{name: "inside an element", input: `[10, 200]`, offset: 2},
+		{name: "after the separator", input: `[10, 200]`, offset: 4},
+		{name: "at the end of the input", input: `[10, 200]`, offset: 9},
+		{name: "after the array", input: `[10, 200] `, offset: 9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := StreamArrayFrom(strings.NewReader(tt.input[tt.offset:]), tt.offset, func(v int, end int64) error {
+				t.Errorf("decoded %d ending at %d", v, end)
+				return nil
+			})
+			if KindOf(err) != KindValidation {
+				t.Errorf("err = %v, want a validation error", err)
+			}
+		})
+	}
+}
+
+func TestAcknowledge(t *testing.T) {
+	// Batches resumed at index 10; every object ends 100 bytes further.
+	batches := []batch{
+		{offset: 10, end: 1300, objects: make([]*models.Object, 3)},
+		{offset: 13, end: 1600, objects: make([]*models.Object, 3)},
+		{offset: 16, end: 1900, objects: make([]*models.Object, 3)},
+		{offset: 19, end: 2000, objects: make([]*models.Object, 1)},
+	}
+	tests := []struct {
+		name         string
+		order        []int
+		acknowledged []int
+		positions    []int64
+	}{
+		{
+			name:         "in order",
+			order:        []int{0, 1, 2, 3},
+			acknowledged: []int{13, 16, 19, 20},
+			positions:    []int64{1300, 1600, 1900, 2000},
+		},
+		{
+			name:         "reversed",
+			order:        []int{3, 2, 1, 0},
+			acknowledged: []int{10, 10, 10, 20},
+			positions:    []int64{0, 0, 0, 2000},
+		},
+		{
+			name:         "gap",
+			order:        []int{0, 2, 3, 1},
+			acknowledged: []int{13, 13, 13, 20},
+			positions:    []int64{1300, 1300, 1300, 2000},
+		},
+		{
+			name:         "pairs swapped",
+			order:        []int{1, 0, 3, 2},
+			acknowledged: []int{10, 16, 16, 20},
+			positions:    []int64{0, 1600, 1600, 2000},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			im := &Importer{stats: ImportStats{Acknowledged: 10}, done: map[int]batch{}}
+			for i, b := range tt.order {
+				im.acknowledge(batches[b])
+				if im.stats.Acknowledged != tt.acknowledged[i] || im.stats.Position != tt.positions[i] {
+					t.Errorf("after batch %d: acknowledged %d at %d, want %d at %d",
+						b, im.stats.Acknowledged, im.stats.Position, tt.acknowledged[i], tt.positions[i])
+				}
+			}
+			if len(im.done) != 0 {
+				t.Errorf("%d batches left waiting", len(im.done))
+			}
+		})
+	}
+}
+
+func TestImporterPosition(t *testing.T) {
+	tests := []struct {
+		name         string
+		workers      int
+		start        int
+		failAt       int
+		acknowledged int
+	}{
+		{name: "one worker", workers: 1, acknowledged: 20},
+		{name: "workers", workers: 4, acknowledged: 20},
+		{name: "resumed", workers: 4, start: 50, acknowledged: 70},
+		{name: "failed batch", workers: 1, failAt: 6, acknowledged: 6},
+		{name: "failed batch with workers", workers: 4, start: 50, failAt: 6, acknowledged: 56},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &fakeWriter{delay: slowFirst}
+			if tt.failAt > 0 {
+				w.fail = func(objects []*models.Object, attempt int) error {
+					if number(objects[0]) == tt.failAt {
+						return Errorf(KindValidation, "batch import", "rejected")
+					}
+					return nil
+				}
+			}
+			var progress []ImportStats
+			im := newImporter(context.Background(), w.write, ImportOptions{
+				BatchSize: 3,
+				Workers:   tt.workers,
+				Start:     tt.start,
+				Progress:  func(s ImportStats) { progress = append(progress, s) },
+			})
+			// Object i of the input ends at byte 10*(i+1).
+			for i, obj := range testObjects(20) {
+				if err := im.AddAt(obj, int64(10*(tt.start+i+1))); err != nil {
+					break
+				}
+			}
+			stats, err := im.Close()
+			if (err != nil) != (tt.failAt > 0) {
+				t.Fatalf("err = %v", err)
+			}
+
+			last := tt.start
+			for _, s := range append(progress, *stats) {
+				if s.Acknowledged < last {
+					t.Errorf("acknowledged went back from %d to %d", last, s.Acknowledged)
+				}
+				last = s.Acknowledged
+				want := int64(10 * s.Acknowledged)
+				if s.Acknowledged == tt.start {
+					want = 0
+				}
+				if s.Position != want {
+					t.Errorf("acknowledged %d at position %d, want %d", s.Acknowledged, s.Position, want)
+				}
+			}
+			if stats.Acknowledged != tt.acknowledged {
+				t.Errorf("acknowledged %d, want %d", stats.Acknowledged, tt.acknowledged)
+			}
+		})
+	}
+}
@@ -0,0 +1,517 @@
+package academy
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/go-openapi/strfmt"
+	"github.com/weaviate/weaviate-go-client/v4/weaviate"
+	"github.com/weaviate/weaviate/entities/models"
+	"gopkg.in/yaml.v3"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Migration is one numbered schema change, read from a file named
+// <version>_<name>.yaml. Down undoes Up; a migration without Down cannot be
+// reverted.
+type Migration struct {
+	Version     int             `json:"version"`
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Up          []MigrationStep `json:"up"`
+	Down        []MigrationStep `json:"down,omitempty"`
+	Checksum    string          `json:"checksum"`
+}
+
+func (mig *Migration) String() string {
+	return fmt.Sprintf("%04d_%s", mig.Version, mig.Name)
+}
+
+// MigrationStep is a single schema operation; exactly one field is set.
+type MigrationStep struct {
+	// CreateClass creates a class, or leaves an identical one alone.
+	CreateClass *models.Class `json:"createClass,omitempty"`
+	// AddProperty adds a property unless the class already has it.
+	AddProperty *AddPropertyStep `json:"addProperty,omitempty"`
+	// UpdateClass sets the settings it lists that the server can change in
+	// place, such as BM25 or ef, and fails if a fixed one differs.
+	UpdateClass *models.Class `json:"updateClass,omitempty"`
+	// DropClass deletes a class and its objects, if it exists.
+	DropClass string `json:"dropClass,omitempty"`
+	// Reindex copies a class into a new definition, see ReindexStep.
+	Reindex *ReindexStep `json:"reindex,omitempty"`
+	// SwitchAlias points an alias at a class.
+	SwitchAlias *SwitchAliasStep `json:"switchAlias,omitempty"`
+}
+
+// AddPropertyStep adds Property to Class.
+type AddPropertyStep struct {
+	Class    string           `json:"class"`
+	Property *models.Property `json:"property"`
+}
+
+// ReindexStep copies every object of From, a class or an alias, into the new
+// class Class and then points Alias, if set, at it. The objects keep their
+// IDs.
+type ReindexStep struct {
+	From        string        `json:"from"`
+	Class       *models.Class `json:"class"`
+	Alias       string        `json:"alias,omitempty"`
+	KeepVectors bool          `json:"keepVectors,omitempty"`
+}
+
+// SwitchAliasStep points the alias Name at Class.
+type SwitchAliasStep struct {
+	Name  string `json:"name"`
+	Class string `json:"class"`
+}
+
+func (s MigrationStep) String() string {
+	switch {
+	case s.CreateClass != nil:
+		return "create class " + s.CreateClass.Class
+	case s.AddProperty != nil:
+		return "add property " + s.AddProperty.Class + "." + s.AddProperty.Property.Name
+	case s.UpdateClass != nil:
+		return "update class " + s.UpdateClass.Class
+	case s.DropClass != "":
+		return "drop class " + s.DropClass
+	case s.Reindex != nil:
+		return "reindex " + s.Reindex.From + " into " + s.Reindex.Class.Class
+	case s.SwitchAlias != nil:
+		return "switch alias " + s.SwitchAlias.Name + " to " + s.SwitchAlias.Class
+	}
+	return "empty step"
+}
+
+func (s MigrationStep) check() error {
+	set := 0
+	if s.CreateClass != nil {
+		set++
+		if err := checkClass(s.CreateClass); err != nil {
+			return err
+		}
+	}
+	if s.AddProperty != nil {
+		set++
+		if s.AddProperty.Class == "" || s.AddProperty.Property == nil || s.AddProperty.Property.Name == "" || len(s.AddProperty.Property.DataType) == 0 {
+			return fmt.Errorf("addProperty needs a class and a property with a name and a dataType")
+		}
+	}
+	if s.UpdateClass != nil {
+		set++
+		if err := checkClass(s.UpdateClass); err != nil {
+			return err
+		}
+		if len(s.UpdateClass.Properties) > 0 {
+			return fmt.Errorf("updateClass changes class settings; use addProperty for properties")
+		}
+	}
+	if s.DropClass != "" {
+		set++
+	}
+	if s.Reindex != nil {
+		set++
+		if s.Reindex.From == "" || s.Reindex.Class == nil {
+			return fmt.Errorf("reindex needs from and class")
+		}
+		if err := checkClass(s.Reindex.Class); err != nil {
+			return err
+		}
+	}
+	if s.SwitchAlias != nil {
+		set++
+		if s.SwitchAlias.Name == "" || s.SwitchAlias.Class == "" {
+			return fmt.Errorf("switchAlias needs name and class")
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("a step sets exactly one of createClass, addProperty, updateClass, dropClass, reindex and switchAlias, got %d", set)
+	}
+	return nil
+}
+
+// ParseMigration decodes the migration file name. Its base name, e.g.
+// 0003_add_air_date.yaml, gives the version and the name of the migration.
+// Unknown fields are rejected.
+func ParseMigration(name string, data []byte) (*Migration, error) {
+	base := strings.TrimSuffix(path.Base(name), path.Ext(name))
+	version, label, ok := strings.Cut(base, "_")
+	n, err := strconv.Atoi(version)
+	if !ok || err != nil || n <= 0 {
+		return nil, Errorf(KindValidation, name, "migration files are named <version>_<name>.yaml with a positive version")
+	}
+
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, Errorf(KindValidation, name, "%v", err)
+	}
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	m := &Migration{}
+	if err := dec.Decode(m); err != nil {
+		return nil, Errorf(KindValidation, name, "%v", err)
+	}
+	if len(m.Up) == 0 {
+		return nil, Errorf(KindValidation, name, "no up steps")
+	}
+	for i, s := range append(append([]MigrationStep{}, m.Up...), m.Down...) {
+		if err := s.check(); err != nil {
+			return nil, fileError(name, fmt.Errorf("step %d: %v", i, err))
+		}
+	}
+	sum := sha256.Sum256(data)
+	m.Version, m.Name, m.Checksum = n, label, hex.EncodeToString(sum[:])
+	return m, nil
+}
+
+// LoadMigrations reads every .yaml file of fsys, e.g. an embed.FS or
+// os.DirFS, sorted by version.
+func LoadMigrations(fsys fs.FS) ([]*Migration, error) {
+	names, err := fs.Glob(fsys, "*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	var migrations []*Migration
+	seen := map[int]string{}
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		m, err := ParseMigration(name, data)
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := seen[m.Version]; ok {
+			return nil, Errorf(KindValidation, name, "version %d is also used by %s", m.Version, other)
+		}
+		seen[m.Version] = name
+		migrations = append(migrations, m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// MigrationState is where a migration stands on a server.
+type MigrationState string
+
+const (
+	MigrationPending MigrationState = "pending"
+	MigrationApplied MigrationState = "applied"
+	// MigrationChanged is an applied migration whose file was edited since.
+	MigrationChanged MigrationState = "changed"
+	// MigrationMissing is an applied migration without a file.
+	MigrationMissing MigrationState = "missing"
+)
+
+// MigrationStatus is a row of the migration history.
+type MigrationStatus struct {
+	Version   int            `json:"version"`
+	Name      string         `json:"name"`
+	State     MigrationState `json:"state"`
+	AppliedAt *time.Time     `json:"appliedAt,omitempty"`
+	Checksum  string         `json:"checksum"`
+}
+
+// Migrator applies migrations and records them in a history class on the
+// server, so every instance knows which of the shared migrations it has run.
+type Migrator struct {
+	Client  *weaviate.Client
+	Aliases *Aliases
+	// HistoryClass defaults to DefaultMigrationClass.
+	HistoryClass string
+}
+
+// DefaultMigrationClass holds the migration history.
+const DefaultMigrationClass = "AcademyMigration"
+
+func (m Migrator) className() string {
+	if m.HistoryClass == "" {
+		return DefaultMigrationClass
+	}
+	return m.HistoryClass
+}
+
+// History returns the applied migrations by version.
+func (m Migrator) History(ctx context.Context) (map[int]MigrationStatus, error) {
+	history := map[int]MigrationStatus{}
+	exists, err := ClassExists(ctx, m.Client, m.className())
+	if err != nil || !exists {
+		return history, err
+	}
+	err = ScanObjects(ctx, m.Client, m.className(), 100, false, func(o *models.Object) error {
+		var s MigrationStatus
+		b, err := json.Marshal(o.Properties)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(b, &s); err != nil {
+			return Errorf(KindValidation, "read migration history", "object %s: %v", o.ID, err)
+		}
+		s.State = MigrationApplied
+		history[s.Version] = s
+		return nil
+	})
+	return history, err
+}
+
+// Status lists migrations and the applied migrations without a file in
+// version order.
+func (m Migrator) Status(ctx context.Context, migrations []*Migration) ([]MigrationStatus, error) {
+	history, err := m.History(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var status []MigrationStatus
+	for _, mig := range migrations {
+		s, ok := history[mig.Version]
+		delete(history, mig.Version)
+		switch {
+		case !ok:
+			s = MigrationStatus{Version: mig.Version, Name: mig.Name, State: MigrationPending, Checksum: mig.Checksum}
+		case s.Checksum != mig.Checksum:
+			s.State = MigrationChanged
+		}
+		status = append(status, s)
+	}
+	for _, s := range history {
+		s.State = MigrationMissing
+		status = append(status, s)
+	}
+	sort.Slice(status, func(i, j int) bool { return status[i].Version < status[j].Version })
+	return status, nil
+}
+
+// UpPlan returns the pending migrations up to and including version to, or
+// all of them if to is 0, in the order Up applies them.
+func (m Migrator) UpPlan(ctx context.Context, migrations []*Migration, to int) ([]*Migration, error) {
+	history, err := m.History(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var plan []*Migration
+	for _, mig := range migrations {
+		if to > 0 && mig.Version > to {
+			break
+		}
+		if _, ok := history[mig.Version]; !ok {
+			plan = append(plan, mig)
+		}
+	}
+	return plan, nil
+}
+
+// Up applies the migrations of UpPlan and returns the ones it applied. It
+// stops at the first failing step; the migrations applied before stay
+// recorded.
+func (m Migrator) Up(ctx context.Context, migrations []*Migration, to int) ([]*Migration, error) {
+	plan, err := m.UpPlan(ctx, migrations, to)
+	if err != nil {
+		return nil, err
+	}
+	var applied []*Migration
+	for _, mig := range plan {
+		if err := m.run(ctx, mig, mig.Up); err != nil {
+			return applied, err
+		}
+		if err := m.record(ctx, mig); err != nil {
+			return applied, err
+		}
+		applied = append(applied, mig)
+	}
+	return applied, nil
+}
+
+// DownPlan returns the latest steps applied migrations, newest first. It
+// fails if steps is below 1 or one of them has no file or no down steps.
+func (m Migrator) DownPlan(ctx context.Context, migrations []*Migration, steps int) ([]*Migration, error) {
+	if steps < 1 {
+		return nil, Errorf(KindValidation, "migrate down", "steps must be at least 1, got %d", steps)
+	}
+	history, err := m.History(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return downPlan(history, migrations, steps)
+}
+
+// downPlan is DownPlan for the applied migrations in history.
+func downPlan(history map[int]MigrationStatus, migrations []*Migration, steps int) ([]*Migration, error) {
+	files := map[int]*Migration{}
+	for _, mig := range migrations {
+		files[mig.Version] = mig
+	}
+	versions := make([]int, 0, len(history))
+	for v := range history {
+		versions = append(versions, v)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+	if steps < len(versions) {
+		versions = versions[:steps]
+	}
+
+	var plan []*Migration
+	for _, v := range versions {
+		mig, ok := files[v]
+		switch {
+		case !ok:
+			return nil, Errorf(KindValidation, "migrate down", "migration %d has no file", v)
+		case len(mig.Down) == 0:
+			return nil, Errorf(KindValidation, "migrate down", "migration %s has no down steps", mig)
+		}
+		plan = append(plan, mig)
+	}
+	return plan, nil
+}
+
+// Down reverts the migrations of DownPlan and returns the ones it reverted.
+// Nothing is changed if DownPlan fails.
+func (m Migrator) Down(ctx context.Context, migrations []*Migration, steps int) ([]*Migration, error) {
+	plan, err := m.DownPlan(ctx, migrations, steps)
+	if err != nil {
+		return nil, err
+	}
+	var reverted []*Migration
+	for _, mig := range plan {
+		if err := m.run(ctx, mig, mig.Down); err != nil {
+			return reverted, err
+		}
+		err := m.Client.Data().Deleter().
+			WithClassName(m.className()).
+			WithID(string(migrationID(mig.Version))).
+			Do(ctx)
+		if err != nil {
+			return reverted, Classify("migrate down", err)
+		}
+		reverted = append(reverted, mig)
+	}
+	return reverted, nil
+}
+
+// Classes returns the classes the up steps of mig create.
+func (mig *Migration) Classes() []*models.Class {
+	var classes []*models.Class
+	for _, s := range mig.Up {
+		switch {
+		case s.CreateClass != nil:
+			classes = append(classes, s.CreateClass)
+		case s.Reindex != nil:
+			classes = append(classes, s.Reindex.Class)
+		}
+	}
+	return classes
+}
+
+func (m Migrator) run(ctx context.Context, mig *Migration, steps []MigrationStep) error {
+	for _, s := range steps {
+		if err := m.apply(ctx, s); err != nil {
+			if e, ok := err.(*Error); ok {
+				e.Op = fmt.Sprintf("migration %s: %s", mig, s)
+				return e
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// apply runs a step. Steps tolerate finding their outcome already in place,
+// so instances set up by hand before the history existed can migrate too.
+func (m Migrator) apply(ctx context.Context, s MigrationStep) error {
+	switch {
+	case s.CreateClass != nil:
+		_, err := EnsureClass(ctx, m.Client, s.CreateClass, false)
+		return err
+	case s.AddProperty != nil:
+		class, err := GetClass(ctx, m.Client, s.AddProperty.Class)
+		if err != nil {
+			return err
+		}
+		if findProperty(class, s.AddProperty.Property.Name) != nil {
+			return nil
+		}
+		return AddProperty(ctx, m.Client, s.AddProperty.Class, s.AddProperty.Property)
+	case s.UpdateClass != nil:
+		return updateSettings(ctx, m.Client, s.UpdateClass)
+	case s.DropClass != "":
+		exists, err := ClassExists(ctx, m.Client, s.DropClass)
+		if err != nil || !exists {
+			return err
+		}
+		return DeleteClass(ctx, m.Client, s.DropClass)
+	case s.Reindex != nil:
+		from, err := m.Aliases.Resolve(ctx, s.Reindex.From)
+		if err != nil {
+			return err
+		}
+		if from != s.Reindex.Class.Class {
+			_, err = CloneClass(ctx, m.Client, CloneOptions{
+				Source:      from,
+				Target:      s.Reindex.Class,
+				BatchSize:   100,
+				KeepIDs:     true,
+				KeepVectors: s.Reindex.KeepVectors,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if s.Reindex.Alias == "" {
+			return nil
+		}
+		_, err = m.Aliases.Switch(ctx, s.Reindex.Alias, s.Reindex.Class.Class)
+		return err
+	case s.SwitchAlias != nil:
+		_, err := m.Aliases.Switch(ctx, s.SwitchAlias.Name, s.SwitchAlias.Class)
+		return err
+	}
+	return nil
+}
+
+func migrationID(version int) strfmt.UUID {
+	return ObjectID(fmt.Sprintf("migration:%d", version))
+}
+
+func (m Migrator) record(ctx context.Context, mig *Migration) error {
+	if _, err := EnsureClass(ctx, m.Client, m.historyClass(), false); err != nil {
+		return err
+	}
+	_, err := ImportObjects(ctx, m.Client, []*models.Object{{
+		Class: m.className(),
+		ID:    migrationID(mig.Version),
+		Properties: map[string]interface{}{
+			"version":   mig.Version,
+			"name":      mig.Name,
+			"checksum":  mig.Checksum,
+			"appliedAt": time.Now().UTC().Format(time.RFC3339Nano),
+		},
+	}})
+	return err
+}
+
+func (m Migrator) historyClass() *models.Class {
+	return &models.Class{
+		Class:       m.className(),
+		Description: "Schema migrations applied by the academy commands",
+		Vectorizer:  "none",
+		Properties: []*models.Property{
+			{Name: "version", DataType: []string{"int"}},
+			{Name: "name", DataType: []string{"text"}, Tokenization: models.PropertyTokenizationField},
+			{Name: "checksum", DataType: []string{"text"}, Tokenization: models.PropertyTokenizationField},
+			{Name: "appliedAt", DataType: []string{"date"}},
+		},
+	}
+}
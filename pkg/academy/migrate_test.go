@@ -0,0 +1,207 @@
+package academy
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestParseMigration(t *testing.T) {
+	const create = `
+up:
+  - createClass: {class: Question, vectorizer: none}
+down:
+  - dropClass: Question
+`
+	tests := []struct {
+		name    string
+		file    string
+		data    string
+		version int
+		label   string
+		err     bool
+	}{
+		{name: "valid", file: "0001_create_question.yaml", data: create, version: 1, label: "create_question"},
+		{name: "directory", file: "migrations/0012_add.yaml", data: create, version: 12, label: "add"},
+		{name: "no up down", file: "0002_x.yaml", data: "up:\n  - dropClass: Question\n", version: 2, label: "x"},
+		{name: "no name", file: "0001.yaml", data: create, err: true},
+		{name: "version zero", file: "0000_x.yaml", data: create, err: true},
+		{name: "not a version", file: "first_x.yaml", data: create, err: true},
+		{name: "not yaml", file: "0001_x.yaml", data: "up: [", err: true},
+		{name: "no up steps", file: "0001_x.yaml", data: "down:\n  - dropClass: Question\n", err: true},
+		{name: "unknown field", file: "0001_x.yaml", data: "up:\n  - dropClass: Question\nsideways: []\n", err: true},
+		{name: "empty step", file: "0001_x.yaml", data: "up:\n  - {}\n", err: true},
+		{name: "two operations", file: "0001_x.yaml", data: "up:\n  - {dropClass: A, switchAlias: {name: a, class: A}}\n", err: true},
+		{name: "property without type", file: "0001_x.yaml", data: "up:\n  - addProperty: {class: A, property: {name: p}}\n", err: true},
+		{name: "update with properties", file: "0001_x.yaml", data: "up:\n  - updateClass: {class: A, properties: [{name: p, dataType: [text]}]}\n", err: true},
+		{name: "bad down step", file: "0001_x.yaml", data: "up:\n  - dropClass: A\ndown:\n  - reindex: {from: A}\n", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := ParseMigration(tt.file, []byte(tt.data))
+			if tt.err {
+				if KindOf(err) != KindValidation {
+					t.Errorf("err = %v, want a validation error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m.Version != tt.version || m.Name != tt.label || len(m.Checksum) != 64 {
+				t.Errorf("got %d %q checksum %q, want %d %q", m.Version, m.Name, m.Checksum, tt.version, tt.label)
+			}
+		})
+	}
+}
+
+func TestParseMigrationChecksum(t *testing.T) {
+	a, err := ParseMigration("0001_x.yaml", []byte("up:\n  - dropClass: A\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ParseMigration("0001_x.yaml", []byte("up:\n  - dropClass: A # same step\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Checksum == b.Checksum {
+		t.Error("an edited file kept its checksum")
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	step := &fstest.MapFile{Data: []byte("up:\n  - dropClass: A\n")}
+	tests := []struct {
+		name     string
+		files    fstest.MapFS
+		versions []int
+		err      bool
+	}{
+		{name: "empty", files: fstest.MapFS{}},
+		{
+			name: "version order",
+			files: fstest.MapFS{
+				"0010_c.yaml": step,
+				"0002_b.yaml": step,
+				"0001_a.yaml": step,
+				"README.md":   {Data: []byte("not a migration")},
+			},
+			versions: []int{1, 2, 10},
+		},
+		{
+			name:     "unpadded",
+			files:    fstest.MapFS{"10_c.yaml": step, "9_b.yaml": step},
+			versions: []int{9, 10},
+		},
+		{
+			name:  "same version",
+			files: fstest.MapFS{"0001_a.yaml": step, "1_b.yaml": step},
+			err:   true,
+		},
+		{
+			name:  "invalid file",
+			files: fstest.MapFS{"0001_a.yaml": step, "0002_b.yaml": {Data: []byte("up: []")}},
+			err:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := LoadMigrations(tt.files)
+			if tt.err {
+				if KindOf(err) != KindValidation {
+					t.Errorf("err = %v, want a validation error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var versions []int
+			for _, m := range migrations {
+				versions = append(versions, m.Version)
+			}
+			if !reflect.DeepEqual(versions, tt.versions) {
+				t.Errorf("versions %v, want %v", versions, tt.versions)
+			}
+		})
+	}
+}
+
+func TestShippedMigrations(t *testing.T) {
+	migrations, err := LoadMigrations(os.DirFS("../../migrations"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no migrations")
+	}
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Errorf("migration %s, want version %d", m, i+1)
+		}
+	}
+}
+
+func TestDownPlan(t *testing.T) {
+	down := []MigrationStep{{DropClass: "A"}}
+	migrations := []*Migration{
+		{Version: 1, Name: "a", Down: down},
+		{Version: 2, Name: "b"},
+		{Version: 3, Name: "c", Down: down},
+		{Version: 4, Name: "d", Down: down},
+	}
+	applied := func(versions ...int) map[int]MigrationStatus {
+		history := map[int]MigrationStatus{}
+		for _, v := range versions {
+			history[v] = MigrationStatus{Version: v, State: MigrationApplied}
+		}
+		return history
+	}
+	tests := []struct {
+		name     string
+		history  map[int]MigrationStatus
+		steps    int
+		versions []int
+		err      bool
+	}{
+		{name: "nothing applied", history: applied(), steps: 1},
+		{name: "latest", history: applied(1, 2, 3, 4), steps: 1, versions: []int{4}},
+		{name: "newest first", history: applied(1, 2, 3, 4), steps: 2, versions: []int{4, 3}},
+		{name: "pending skipped", history: applied(1, 3), steps: 2, versions: []int{3, 1}},
+		{name: "more steps than applied", history: applied(3, 4), steps: 10, versions: []int{4, 3}},
+		{name: "no down steps", history: applied(1, 2, 3, 4), steps: 3, err: true},
+		{name: "no file", history: applied(1, 5), steps: 1, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := downPlan(tt.history, migrations, tt.steps)
+			if tt.err {
+				if KindOf(err) != KindValidation {
+					t.Errorf("err = %v, want a validation error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var versions []int
+			for _, m := range plan {
+				versions = append(versions, m.Version)
+			}
+			if !reflect.DeepEqual(versions, tt.versions) {
+				t.Errorf("versions %v, want %v", versions, tt.versions)
+			}
+		})
+	}
+}
+
+func TestDownPlanSteps(t *testing.T) {
+	// Steps are checked before the history is read, so no client is needed.
+	for _, steps := range []int{0, -1} {
+		if _, err := (Migrator{}).DownPlan(context.Background(), nil, steps); KindOf(err) != KindValidation {
+			t.Errorf("DownPlan(%d) err = %v, want a validation error", steps, err)
+		}
+	}
+}
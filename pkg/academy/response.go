@@ -0,0 +1,109 @@
+package academy
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/weaviate/weaviate/entities/models"
+	"sort"
+	"strings"
+)
+
+// Problem is an error reported inside an otherwise successful GraphQL
+// response, located by its path in the response.
+type Problem struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (p Problem) String() string {
+	if p.Path == "" {
+		return p.Message
+	}
+	return p.Path + ": " + p.Message
+}
+
+// Problems returns the top level GraphQL errors of res followed by the
+// errors of generative and ask results in each returned object.
+func Problems(res *models.GraphQLResponse) []Problem {
+	if res == nil {
+		return nil
+	}
+	var problems []Problem
+	for _, e := range res.Errors {
+		problems = append(problems, Problem{Path: strings.Join(e.Path, "."), Message: e.Message})
+	}
+
+	ops := make([]string, 0, len(res.Data))
+	for op := range res.Data {
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+	for _, op := range ops {
+		classes, ok := res.Data[op].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		names := make([]string, 0, len(classes))
+		for name := range classes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			objects, _ := classes[name].([]interface{})
+			for i, obj := range objects {
+				path := fmt.Sprintf("%s.%s[%d]._additional", op, name, i)
+				problems = append(problems, additionalProblems(path, obj)...)
+			}
+		}
+	}
+	return problems
+}
+
+// additionalProblems collects the non-empty "error" fields of the objects
+// below _additional, such as generate and answer.
+func additionalProblems(path string, obj interface{}) []Problem {
+	o, _ := obj.(map[string]interface{})
+	additional, _ := o["_additional"].(map[string]interface{})
+	keys := make([]string, 0, len(additional))
+	for k := range additional {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var problems []Problem
+	for _, k := range keys {
+		sub, _ := additional[k].(map[string]interface{})
+		if msg, _ := sub["error"].(string); msg != "" {
+			problems = append(problems, Problem{Path: path + "." + k + ".error", Message: msg})
+		}
+	}
+	return problems
+}
+
+// CheckResponse returns a KindGraphQL error listing the problems in res, or
+// nil if there are none. A query against a class missing from the schema is
+// reported as KindClassNotFound.
+func CheckResponse(op string, res *models.GraphQLResponse) error {
+	problems := Problems(res)
+	if len(problems) == 0 {
+		return nil
+	}
+
+	kind := KindGraphQL
+	msgs := make([]string, len(problems))
+	for i, p := range problems {
+		msgs[i] = p.String()
+		if strings.HasPrefix(p.Message, "Cannot query field") &&
+			(strings.Contains(p.Message, `on type "GetObjectsObj"`) || strings.Contains(p.Message, `on type "AggregateObjectsObj"`)) {
+			kind = KindClassNotFound
+		}
+	}
+	detail, _ := json.Marshal(problems)
+	return &Error{
+		Kind:     kind,
+		Op:       op,
+		Msg:      strings.Join(msgs, "; "),
+		Detail:   string(detail),
+		Problems: problems,
+	}
+}
@@ -0,0 +1,145 @@
+package academy
+
+import (
+	"encoding/json"
+	"github.com/weaviate/weaviate/entities/models"
+	"reflect"
+	"testing"
+)
+
+// response decodes a GraphQL response body.
+func response(t *testing.T, body string) *models.GraphQLResponse {
+	t.Helper()
+	var res models.GraphQLResponse
+	if err := json.Unmarshal([]byte(body), &res); err != nil {
+		t.Fatal(err)
+	}
+	return &res
+}
+
+func TestProblems(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []Problem
+	}{
+		{
+			name: "none",
+			body: `{"data": {"Get": {"WikiCity": [{"city_name": "Paris", "_additional": {"id": "x"}}]}}}`,
+		},
+		{
+			name: "top level",
+			body: `{"errors": [{"message": "syntax error", "path": ["Get", "WikiCity"]}, {"message": "no path"}]}`,
+			want: []Problem{
+				{Path: "Get.WikiCity", Message: "syntax error"},
+				{Message: "no path"},
+			},
+		},
+		{
+			name: "generate and answer errors",
+			body: `{"data": {"Get": {"WikiCity": [
+				{"_additional": {"generate": {"singleResult": "ok", "error": null}}},
+				{"_additional": {"generate": {"error": "rate limited"}, "answer": {"error": "no key"}}}
+			]}}}`,
+			want: []Problem{
+				{Path: "Get.WikiCity[1]._additional.answer.error", Message: "no key"},
+				{Path: "Get.WikiCity[1]._additional.generate.error", Message: "rate limited"},
+			},
+		},
+		{
+			name: "empty error",
+			body: `{"data": {"Get": {"WikiCity": [{"_additional": {"generate": {"error": ""}}}]}}}`,
+		},
+		{
+			name: "errors before objects",
+			body: `{"errors": [{"message": "partial"}], "data": {"Get": {
+				"B": [{"_additional": {"generate": {"error": "b"}}}],
+				"A": [{"_additional": {"generate": {"error": "a"}}}]
+			}}}`,
+			want: []Problem{
+				{Message: "partial"},
+				{Path: "Get.A[0]._additional.generate.error", Message: "a"},
+				{Path: "Get.B[0]._additional.generate.error", Message: "b"},
+			},
+		},
+		{
+			name: "aggregate",
+			body: `{"data": {"Aggregate": {"JeopardyQuestion": [{"meta": {"count": 10}}]}}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Problems(response(t, tt.body)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if got := Problems(nil); got != nil {
+		t.Errorf("Problems(nil) = %v", got)
+	}
+}
+
+func TestCheckResponse(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		kind Kind
+		msg  string
+	}{
+		{
+			name: "ok",
+			body: `{"data": {"Get": {"WikiCity": []}}}`,
+		},
+		{
+			name: "graphql",
+			body: `{"errors": [{"message": "a"}, {"message": "b", "path": ["Get"]}]}`,
+			kind: KindGraphQL,
+			msg:  "a; Get: b",
+		},
+		{
+			name: "unknown get class",
+			body: `{"errors": [{"message": "Cannot query field \"Nope\" on type \"GetObjectsObj\"."}]}`,
+			kind: KindClassNotFound,
+			msg:  `Cannot query field "Nope" on type "GetObjectsObj".`,
+		},
+		{
+			name: "unknown aggregate class",
+			body: `{"errors": [{"message": "Cannot query field \"Nope\" on type \"AggregateObjectsObj\"."}]}`,
+			kind: KindClassNotFound,
+			msg:  `Cannot query field "Nope" on type "AggregateObjectsObj".`,
+		},
+		{
+			name: "unknown property",
+			body: `{"errors": [{"message": "Cannot query field \"nope\" on type \"WikiCity\"."}]}`,
+			kind: KindGraphQL,
+			msg:  `Cannot query field "nope" on type "WikiCity".`,
+		},
+		{
+			name: "generate error",
+			body: `{"data": {"Get": {"WikiCity": [{"_additional": {"generate": {"error": "rate limited"}}}]}}}`,
+			kind: KindGraphQL,
+			msg:  "Get.WikiCity[0]._additional.generate.error: rate limited",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckResponse("search", response(t, tt.body))
+			if tt.kind == KindUnknown {
+				if err != nil {
+					t.Fatalf("err = %v", err)
+				}
+				return
+			}
+			e, ok := err.(*Error)
+			if !ok {
+				t.Fatalf("err = %#v, want an *Error", err)
+			}
+			if e.Kind != tt.kind || e.Op != "search" || e.Msg != tt.msg {
+				t.Errorf("got %v %q: %q, want %v %q: %q", e.Kind, e.Op, e.Msg, tt.kind, "search", tt.msg)
+			}
+			if len(e.Problems) == 0 {
+				t.Error("no problems attached")
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package academy
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/weaviate/weaviate-go-client/v4/weaviate/fault"
+	"github.com/weaviate/weaviate/entities/models"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Transient reports whether err, as returned by the academy functions, may
+// go away when the request is repeated: the server was unreachable, timed
+// out, rate limited the request or failed with a 5xx status.
+func Transient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var ce *fault.WeaviateClientError
+	if errors.As(err, &ce) && ce.IsUnexpectedStatusCode {
+		return ce.StatusCode == http.StatusTooManyRequests || ce.StatusCode >= 500
+	}
+	return KindOf(err) == KindUnreachable
+}
+
+// transientMessages are fragments of per object batch errors that come from
+// an overloaded or slow vectorizer rather than from the object itself.
+var transientMessages = []string{
+	"timeout",
+	"timed out",
+	"deadline exceeded",
+	"rate limit",
+	"too many requests",
+	"429",
+	"connection reset",
+	"connection refused",
+	"unexpected eof",
+	"status code: 5",
+	"500 internal server error",
+	"502 bad gateway",
+	"503 service unavailable",
+	"504 gateway timeout",
+}
+
+// transientFailure reports whether every message of f looks transient.
+func transientFailure(f BatchFailure) bool {
+	if len(f.Messages) == 0 {
+		return false
+	}
+	for _, msg := range f.Messages {
+		msg = strings.ToLower(msg)
+		transient := false
+		for _, t := range transientMessages {
+			if strings.Contains(msg, t) {
+				transient = true
+				break
+			}
+		}
+		if !transient {
+			return false
+		}
+	}
+	return true
+}
+
+// Backoff is an exponential retry policy with jitter.
+type Backoff struct {
+	// Retries is the number of attempts after the first, 0 to never retry.
+	Retries int
+	// Initial is the delay before the first retry, default 500ms. It doubles
+	// with every retry up to Max, default 30s.
+	Initial time.Duration
+	Max     time.Duration
+}
+
+// delay returns how long to wait before retry number attempt, counting from
+// 1: the doubled delay, less up to a quarter at random so workers that
+// failed together do not retry together.
+func (b Backoff) delay(attempt int) time.Duration {
+	d, max := b.Initial, b.Max
+	if d <= 0 {
+		d = 500 * time.Millisecond
+	}
+	if max <= 0 {
+		max = 30 * time.Second
+	}
+	for i := 1; i < attempt && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	return d - time.Duration(rand.Int63n(int64(d)/4+1))
+}
+
+// wait sleeps before retry number attempt, returning false if ctx is done
+// first.
+func (b Backoff) wait(ctx context.Context, attempt int) bool {
+	t := time.NewTimer(b.delay(attempt))
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// DeadLetter is an object that could not be imported, as written to the
+// dead-letter file of an Importer, one JSON object per line. Index is its
+// position among the objects added to the Importer.
+type DeadLetter struct {
+	Index    int            `json:"index"`
+	Messages []string       `json:"messages"`
+	Attempts int            `json:"attempts"`
+	Object   *models.Object `json:"object"`
+}
+
+// ReadDeadLetters decodes the dead-letter lines read from r and calls fn
+// with each.
+func ReadDeadLetters(r io.Reader, fn func(DeadLetter) error) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		if len(strings.TrimSpace(sc.Text())) == 0 {
+			continue
+		}
+		var dl DeadLetter
+		if err := json.Unmarshal(sc.Bytes(), &dl); err != nil {
+			return Errorf(KindValidation, "read dead letters", "line %d: %v", line, err)
+		}
+		if dl.Object == nil {
+			return Errorf(KindValidation, "read dead letters", "line %d: no object", line)
+		}
+		if err := fn(dl); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
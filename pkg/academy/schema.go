@@ -0,0 +1,141 @@
+package academy
+
+import (
+	"context"
+	"github.com/weaviate/weaviate-go-client/v4/weaviate"
+	"github.com/weaviate/weaviate-go-client/v4/weaviate/schema"
+	"github.com/weaviate/weaviate/entities/models"
+)
+
+// GetSchema returns every class defined on the server.
+func GetSchema(ctx context.Context, client *weaviate.Client) (*schema.Dump, error) {
+	dump, err := client.Schema().Getter().Do(ctx)
+	if err != nil {
+		return nil, Classify("get schema", err)
+	}
+	return dump, nil
+}
+
+// GetClass returns the definition of className.
+func GetClass(ctx context.Context, client *weaviate.Client, className string) (*models.Class, error) {
+	class, err := client.Schema().ClassGetter().WithClassName(className).Do(ctx)
+	if err != nil {
+		return nil, Classify("get class "+className, err)
+	}
+	return class, nil
+}
+
+// GetMeta returns the server version, hostname and enabled modules.
+func GetMeta(ctx context.Context, client *weaviate.Client) (*models.Meta, error) {
+	meta, err := client.Misc().MetaGetter().Do(ctx)
+	if err != nil {
+		return nil, Classify("get meta", err)
+	}
+	return meta, nil
+}
+
+// CreateClass creates class.
+func CreateClass(ctx context.Context, client *weaviate.Client, class *models.Class) error {
+	err := client.Schema().ClassCreator().
+		WithClass(class).
+		Do(ctx)
+	return Classify("create class "+class.Class, err)
+}
+
+// DeleteClass deletes className together with all of its objects.
+func DeleteClass(ctx context.Context, client *weaviate.Client, className string) error {
+	err := client.Schema().ClassDeleter().
+		WithClassName(className).
+		Do(ctx)
+	return Classify("delete class "+className, err)
+}
+
+// UpdateClass replaces the mutable settings of the existing class.Class,
+// such as the BM25 parameters and stopwords, with those of class. class must
+// be a complete definition, e.g. a changed copy of GetClass.
+func UpdateClass(ctx context.Context, client *weaviate.Client, class *models.Class) error {
+	err := client.Schema().ClassUpdater().
+		WithClass(class).
+		Do(ctx)
+	return Classify("update class "+class.Class, err)
+}
+
+// AddProperty adds property to the existing className. Objects imported
+// before have no value for it.
+func AddProperty(ctx context.Context, client *weaviate.Client, className string, property *models.Property) error {
+	err := client.Schema().PropertyCreator().
+		WithClassName(className).
+		WithProperty(property).
+		Do(ctx)
+	return Classify("add property "+className+"."+property.Name, err)
+}
+
+// ClassExists reports whether className is defined on the server.
+func ClassExists(ctx context.Context, client *weaviate.Client, className string) (bool, error) {
+	exists, err := client.Schema().ClassExistenceChecker().
+		WithClassName(className).
+		Do(ctx)
+	if err != nil {
+		return false, Classify("check class "+className, err)
+	}
+	return exists, nil
+}
+
+// EnsureOutcome is what EnsureClass did.
+type EnsureOutcome string
+
+const (
+	EnsureCreated   EnsureOutcome = "created"
+	EnsureUnchanged EnsureOutcome = "unchanged"
+	EnsureDrifted   EnsureOutcome = "drifted"
+	EnsureRecreated EnsureOutcome = "recreated"
+)
+
+// EnsureResult reports the outcome of EnsureClass. Drift lists how the live
+// class differs from the desired one.
+type EnsureResult struct {
+	Class   string        `json:"class"`
+	Outcome EnsureOutcome `json:"outcome"`
+	Drift   []Change      `json:"drift,omitempty"`
+}
+
+// EnsureClass makes sure class exists. A missing class is created and an
+// identical one left alone. If the live class has drifted from class it is
+// deleted, with all of its objects, and created again when recreate is set;
+// otherwise the drift is returned together with a KindValidation error.
+func EnsureClass(ctx context.Context, client *weaviate.Client, class *models.Class, recreate bool) (*EnsureResult, error) {
+	result := &EnsureResult{Class: class.Class}
+	exists, err := ClassExists(ctx, client, class.Class)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		result.Outcome = EnsureCreated
+		return result, CreateClass(ctx, client, class)
+	}
+
+	live, err := GetClass(ctx, client, class.Class)
+	if err != nil {
+		return nil, err
+	}
+	diff, err := DiffSchema([]*models.Class{class}, []*models.Class{live})
+	if err != nil {
+		return nil, err
+	}
+	result.Drift = diff.Changes
+	switch {
+	case len(diff.Changes) == 0:
+		result.Outcome = EnsureUnchanged
+		return result, nil
+	case !recreate:
+		result.Outcome = EnsureDrifted
+		return result, Errorf(KindValidation, "ensure class "+class.Class,
+			"class exists with %d difference(s), first: %s; recreate to replace it", len(diff.Changes), diff.Changes[0])
+	}
+
+	if err := DeleteClass(ctx, client, class.Class); err != nil {
+		return nil, err
+	}
+	result.Outcome = EnsureRecreated
+	return result, CreateClass(ctx, client, class)
+}
@@ -0,0 +1,204 @@
+package academy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/weaviate/weaviate/entities/models"
+	"gopkg.in/yaml.v3"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ParseClasses decodes class definitions from YAML or JSON. data may hold a
+// single class, a list of classes or a schema dump with a classes key. Field
+// names are those of the REST API, e.g. dataType and moduleConfig; unknown
+// fields are rejected so typos do not silently fall back to defaults.
+func ParseClasses(data []byte) ([]*models.Class, error) {
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	var items []interface{}
+	switch v := doc.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		items = v
+	case map[string]interface{}:
+		if classes, ok := v["classes"].([]interface{}); ok {
+			items = classes
+		} else {
+			items = []interface{}{v}
+		}
+	default:
+		return nil, Errorf(KindValidation, "parse classes", "expected a class, a list of classes or a schema")
+	}
+
+	classes := make([]*models.Class, len(items))
+	for i, item := range items {
+		b, err := json.Marshal(item)
+		if err != nil {
+			return nil, err
+		}
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.DisallowUnknownFields()
+		var class models.Class
+		if err := dec.Decode(&class); err != nil {
+			return nil, Errorf(KindValidation, "parse classes", "class %d: %v", i, err)
+		}
+		if err := checkClass(&class); err != nil {
+			return nil, err
+		}
+		classes[i] = &class
+	}
+	return classes, nil
+}
+
+func checkClass(class *models.Class) error {
+	if class.Class == "" {
+		return Errorf(KindValidation, "parse classes", "class without a name")
+	}
+	if err := checkInvertedIndex(class); err != nil {
+		return err
+	}
+	if err := checkVectorIndex(class); err != nil {
+		return err
+	}
+	for i, p := range class.Properties {
+		if p == nil || p.Name == "" {
+			return Errorf(KindValidation, "parse classes", "%s: property %d has no name", class.Class, i)
+		}
+		if len(p.DataType) == 0 {
+			return Errorf(KindValidation, "parse classes", "%s.%s: no dataType", class.Class, p.Name)
+		}
+	}
+	return nil
+}
+
+// checkInvertedIndex rejects BM25 parameters and stopword presets the server
+// would refuse.
+func checkInvertedIndex(class *models.Class) error {
+	cfg := class.InvertedIndexConfig
+	if cfg == nil {
+		return nil
+	}
+	if bm25 := cfg.Bm25; bm25 != nil {
+		if bm25.K1 < 0 {
+			return Errorf(KindValidation, "parse classes", "%s: bm25 k1 must not be negative, got %v", class.Class, bm25.K1)
+		}
+		if bm25.B < 0 || bm25.B > 1 {
+			return Errorf(KindValidation, "parse classes", "%s: bm25 b must be between 0 and 1, got %v", class.Class, bm25.B)
+		}
+	}
+	if sw := cfg.Stopwords; sw != nil {
+		switch sw.Preset {
+		case "", "en", "none":
+		default:
+			return Errorf(KindValidation, "parse classes", "%s: unknown stopword preset %q, want en or none", class.Class, sw.Preset)
+		}
+	}
+	return nil
+}
+
+// distances are the metrics an HNSW or flat index can use.
+var distances = map[string]bool{"cosine": true, "dot": true, "l2-squared": true, "hamming": true, "manhattan": true}
+
+// checkVectorIndex rejects vector index settings the server would refuse.
+func checkVectorIndex(class *models.Class) error {
+	switch class.VectorIndexType {
+	case "", "hnsw", "flat":
+	default:
+		return Errorf(KindValidation, "parse classes", "%s: unknown vectorIndexType %q, want hnsw or flat", class.Class, class.VectorIndexType)
+	}
+	cfg, _ := class.VectorIndexConfig.(map[string]interface{})
+	if d, ok := cfg["distance"]; ok && !distances[fmt.Sprint(d)] {
+		return Errorf(KindValidation, "parse classes", "%s: unknown distance %q", class.Class, d)
+	}
+	for _, key := range []string{"efConstruction", "maxConnections"} {
+		if v, ok := cfg[key].(float64); ok && v <= 0 {
+			return Errorf(KindValidation, "parse classes", "%s: %s must be positive, got %v", class.Class, key, v)
+		}
+	}
+	if v, ok := cfg["ef"].(float64); ok && v < -1 {
+		return Errorf(KindValidation, "parse classes", "%s: ef must be -1 (dynamic) or positive, got %v", class.Class, v)
+	}
+	pq, _ := cfg["pq"].(map[string]interface{})
+	if v, ok := pq["centroids"].(float64); ok && (v < 1 || v > 256) {
+		return Errorf(KindValidation, "parse classes", "%s: pq centroids must be between 1 and 256, got %v", class.Class, v)
+	}
+	return nil
+}
+
+// LoadClasses reads class definitions from a file, or from every .yaml, .yml
+// and .json file in a directory in name order.
+func LoadClasses(path string) ([]*models.Class, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return loadClassDir(os.DirFS(path), path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseClassFile(path, data)
+}
+
+// LoadClassesFS is LoadClasses for the directory at the root of fsys, such
+// as embedded definitions.
+func LoadClassesFS(fsys fs.FS) ([]*models.Class, error) {
+	return loadClassDir(fsys, "")
+}
+
+// loadClassDir reads the class files of fsys, naming them in errors as if
+// they were in dir.
+func loadClassDir(fsys fs.FS, dir string) ([]*models.Class, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	var classes []*models.Class
+	for _, e := range entries {
+		switch strings.ToLower(path.Ext(e.Name())) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+		data, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := parseClassFile(filepath.Join(dir, e.Name()), data)
+		if err != nil {
+			return nil, err
+		}
+		classes = append(classes, parsed...)
+	}
+	return classes, nil
+}
+
+func parseClassFile(file string, data []byte) ([]*models.Class, error) {
+	classes, err := ParseClasses(data)
+	if err != nil {
+		return nil, fileError(file, err)
+	}
+	return classes, nil
+}
+
+// fileError reports err as a validation error of file, keeping the kind of
+// an already classified err.
+func fileError(file string, err error) error {
+	var e *Error
+	if errors.As(err, &e) {
+		return &Error{Kind: e.Kind, Op: file, Msg: e.Msg, Detail: e.Detail, Problems: e.Problems, Err: err}
+	}
+	return &Error{Kind: KindValidation, Op: file, Msg: err.Error(), Err: err}
+}
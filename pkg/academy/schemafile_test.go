@@ -0,0 +1,80 @@
+package academy
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadClassesFS(t *testing.T) {
+	class := func(name string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte("class: " + name + "\nproperties:\n  - {name: title, dataType: [text]}\n")}
+	}
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		classes []string
+		err     string
+	}{
+		{name: "empty", files: fstest.MapFS{}},
+		{
+			name: "name order",
+			files: fstest.MapFS{
+				"b.yml":     class("B"),
+				"a.yaml":    class("A"),
+				"c.json":    {Data: []byte(`{"class": "C"}`)},
+				"README.md": {Data: []byte("not a class")},
+			},
+			classes: []string{"A", "B", "C"},
+		},
+		{
+			name:  "invalid file",
+			files: fstest.MapFS{"a.yaml": class("A"), "b.yaml": {Data: []byte("class: B\nproperty: []\n")}},
+			err:   "b.yaml: ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			classes, err := LoadClassesFS(tt.files)
+			if tt.err != "" {
+				if KindOf(err) != KindValidation || !strings.HasPrefix(fmt.Sprint(err), tt.err) {
+					t.Errorf("err = %v, want a validation error starting with %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, c := range classes {
+				names = append(names, c.Class)
+			}
+			if !reflect.DeepEqual(names, tt.classes) {
+				t.Errorf("classes %v, want %v", names, tt.classes)
+			}
+		})
+	}
+}
+
+func TestFileError(t *testing.T) {
+	parse := Errorf(KindValidation, "parse classes", "A: unknown distance %q", "euclid")
+	err := fileError("schemas/A.yaml", parse)
+	if got, want := err.Error(), `schemas/A.yaml: A: unknown distance "euclid"`; got != want {
+		t.Errorf("err = %q, want %q", got, want)
+	}
+	if !errors.Is(err, parse) {
+		t.Errorf("err does not wrap %v", parse)
+	}
+	if parse.Op != "parse classes" {
+		t.Errorf("the wrapped error was changed to op %q", parse.Op)
+	}
+
+	other := errors.New("yaml: line 1: did not find expected key")
+	err = fileError("a.yaml", other)
+	if KindOf(err) != KindValidation || !errors.Is(err, other) {
+		t.Errorf("err = %v, want a validation error wrapping %v", err, other)
+	}
+}
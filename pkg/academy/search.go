@@ -0,0 +1,171 @@
+package academy
+
+import (
+	"context"
+	"github.com/weaviate/weaviate-go-client/v4/weaviate"
+	"github.com/weaviate/weaviate-go-client/v4/weaviate/filters"
+	"github.com/weaviate/weaviate-go-client/v4/weaviate/graphql"
+	"github.com/weaviate/weaviate/entities/models"
+)
+
+// Search describes a Get query. At most one of NearText, NearObject, BM25
+// and Hybrid should be set; Where, Generative and Ask may be combined with
+// them.
+type Search struct {
+	ClassName  string
+	Properties []string
+	Additional []graphql.Field
+	Limit      int
+
+	NearText   *NearText
+	NearObject *NearObject
+	BM25       *BM25
+	Hybrid     *Hybrid
+	Where      *filters.WhereBuilder
+	Generative *Generative
+	Ask        *Ask
+}
+
+// NearText searches by concepts. Zero Distance means no distance limit.
+type NearText struct {
+	Concepts []string
+	Distance float32
+}
+
+// NearObject searches near an existing object.
+type NearObject struct {
+	ID       string
+	Distance float32
+}
+
+// BM25 is a keyword search. Properties may carry ^weights, e.g. question^2.
+type BM25 struct {
+	Query      string
+	Properties []string
+}
+
+// Hybrid combines BM25 and vector search. A nil Alpha uses the server
+// default.
+type Hybrid struct {
+	Query      string
+	Properties []string
+	Alpha      *float32
+}
+
+// Generative adds a generative search. SingleResult prompts may reference
+// properties as {name}; GroupedResult is a task over all results.
+type Generative struct {
+	SingleResult  string
+	GroupedResult string
+}
+
+// Ask is a question answering search over Properties.
+type Ask struct {
+	Question   string
+	Properties []string
+}
+
+// Names returns a field per name, for Search.Additional.
+func Names(names ...string) []graphql.Field {
+	fields := make([]graphql.Field, len(names))
+	for i, name := range names {
+		fields[i] = graphql.Field{Name: name}
+	}
+	return fields
+}
+
+func (s Search) fields() []graphql.Field {
+	fields := Names(s.Properties...)
+	if len(s.Additional) > 0 {
+		fields = append(fields, graphql.Field{Name: "_additional", Fields: s.Additional})
+	}
+	return fields
+}
+
+func (s Search) builder(client *weaviate.Client) *graphql.GetBuilder {
+	gql := client.GraphQL()
+	get := gql.Get().
+		WithClassName(s.ClassName).
+		WithFields(s.fields()...)
+	if s.Limit > 0 {
+		get = get.WithLimit(s.Limit)
+	}
+	if s.NearText != nil {
+		get = get.WithNearText(s.NearText.builder(client))
+	}
+	if s.NearObject != nil {
+		nearObject := gql.NearObjectArgBuilder().WithID(s.NearObject.ID)
+		if s.NearObject.Distance > 0 {
+			nearObject = nearObject.WithDistance(s.NearObject.Distance)
+		}
+		get = get.WithNearObject(nearObject)
+	}
+	if s.BM25 != nil {
+		get = get.WithBM25(gql.Bm25ArgBuilder().
+			WithQuery(s.BM25.Query).
+			WithProperties(s.BM25.Properties...))
+	}
+	if s.Hybrid != nil {
+		hybrid := gql.HybridArgumentBuilder().
+			WithQuery(s.Hybrid.Query).
+			WithProperties(s.Hybrid.Properties)
+		if s.Hybrid.Alpha != nil {
+			hybrid = hybrid.WithAlpha(*s.Hybrid.Alpha)
+		}
+		get = get.WithHybrid(hybrid)
+	}
+	if s.Where != nil {
+		get = get.WithWhere(s.Where)
+	}
+	if s.Generative != nil {
+		generative := graphql.NewGenerativeSearch()
+		if s.Generative.SingleResult != "" {
+			generative = generative.SingleResult(s.Generative.SingleResult)
+		}
+		if s.Generative.GroupedResult != "" {
+			generative = generative.GroupedResult(s.Generative.GroupedResult)
+		}
+		get = get.WithGenerativeSearch(generative)
+	}
+	if s.Ask != nil {
+		get = get.WithAsk(gql.AskArgBuilder().
+			WithQuestion(s.Ask.Question).
+			WithProperties(s.Ask.Properties))
+	}
+	return get
+}
+
+func (n *NearText) builder(client *weaviate.Client) *graphql.NearTextArgumentBuilder {
+	nearText := client.GraphQL().NearTextArgBuilder().
+		WithConcepts(n.Concepts)
+	if n.Distance > 0 {
+		nearText = nearText.WithDistance(n.Distance)
+	}
+	return nearText
+}
+
+// Run executes the search. When the response contains GraphQL or per object
+// errors it is returned together with a KindGraphQL error, so callers may
+// still use the partial results.
+func (s Search) Run(ctx context.Context, client *weaviate.Client) (*models.GraphQLResponse, error) {
+	op := "search " + s.ClassName
+	res, err := s.builder(client).Do(ctx)
+	if err != nil {
+		return nil, Classify(op, err)
+	}
+	return res, CheckResponse(op, res)
+}
+
+// SearchRows executes s and decodes the returned objects into rows of type T.
+func SearchRows[T any](ctx context.Context, client *weaviate.Client, s Search) ([]T, error) {
+	return Get[T](ctx, s.builder(client), s.ClassName)
+}
+
+// Raw executes a GraphQL query string, checking the response like Search.Run.
+func Raw(ctx context.Context, client *weaviate.Client, query string) (*models.GraphQLResponse, error) {
+	res, err := client.GraphQL().Raw().WithQuery(query).Do(ctx)
+	if err != nil {
+		return nil, Classify("raw query", err)
+	}
+	return res, CheckResponse("raw query", res)
+}
@@ -0,0 +1,88 @@
+package academy
+
+import (
+	"context"
+	"github.com/weaviate/weaviate-go-client/v4/weaviate"
+)
+
+// VectorIndexUpdate reports UpdateVectorIndex: the vector index settings of
+// Class before and after, and the changed fields.
+type VectorIndexUpdate struct {
+	Class   string                 `json:"class"`
+	Before  map[string]interface{} `json:"before"`
+	After   map[string]interface{} `json:"after"`
+	Changes []Change               `json:"changes"`
+	Applied bool                   `json:"applied"`
+}
+
+// UpdateVectorIndex merges settings, e.g. {"ef": 128, "pq": {"enabled":
+// true}}, into the vectorIndexConfig of className. Only the settings the
+// server can change in place are accepted; distance, efConstruction and
+// maxConnections need a new class. With dryRun, or when nothing changes, the
+// class is left alone and After is the would-be configuration.
+func UpdateVectorIndex(ctx context.Context, client *weaviate.Client, className string, settings map[string]interface{}, dryRun bool) (*VectorIndexUpdate, error) {
+	op := "update vector index " + className
+	live, err := GetClass(ctx, client, className)
+	if err != nil {
+		return nil, err
+	}
+	h, err := genericMap(live)
+	if err != nil {
+		return nil, err
+	}
+	w, err := genericMap(map[string]interface{}{"vectorIndexConfig": settings})
+	if err != nil {
+		return nil, err
+	}
+	before, _ := h["vectorIndexConfig"].(map[string]interface{})
+	update := &VectorIndexUpdate{Class: className, Before: before}
+
+	fields := subsetDiff("", w, h, nil)
+	for _, field := range fields {
+		if !mutable(field) {
+			return nil, Errorf(KindValidation, op, "%s cannot be changed in place; clone the class with the new setting instead", field)
+		}
+		update.Changes = append(update.Changes, Change{
+			Class:    className,
+			Action:   ActionChange,
+			Field:    field,
+			Want:     encode(lookup(w, field)),
+			Have:     encode(lookup(h, field)),
+			Additive: true,
+		})
+	}
+
+	// Merge on a copy so Before keeps the live values.
+	merged, err := genericMap(h)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range fields {
+		setPath(merged, field, lookup(w, field))
+	}
+	update.After, _ = merged["vectorIndexConfig"].(map[string]interface{})
+	class, err := classFromMap(merged)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVectorIndex(class); err != nil {
+		return nil, fileError(op, err)
+	}
+	if dryRun || len(fields) == 0 {
+		return update, nil
+	}
+	if err := UpdateClass(ctx, client, class); err != nil {
+		return nil, err
+	}
+	updated, err := GetClass(ctx, client, className)
+	if err != nil {
+		return nil, err
+	}
+	after, err := genericMap(updated)
+	if err != nil {
+		return nil, err
+	}
+	update.After, _ = after["vectorIndexConfig"].(map[string]interface{})
+	update.Applied = true
+	return update, nil
+}
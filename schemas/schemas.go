@@ -0,0 +1,42 @@
+// Package schemas embeds the class definitions the commands create. Each
+// <Class>.yaml file is in the format read by academy.ParseClasses and can be
+// applied directly with `schemas_imports schema apply`.
+package schemas
+
+import (
+	"embed"
+	"example.com/weaviate-tutorial/pkg/academy"
+	"github.com/weaviate/weaviate/entities/models"
+	"strings"
+)
+
+//go:embed *.yaml
+var files embed.FS
+
+// Class returns the definition of className.
+func Class(className string) (*models.Class, error) {
+	data, err := files.ReadFile(className + ".yaml")
+	if err != nil {
+		return nil, academy.Errorf(academy.KindValidation, "schema", "no definition for class %q, have %s", className, strings.Join(Names(), ", "))
+	}
+	classes, err := academy.ParseClasses(data)
+	if err != nil {
+		return nil, err
+	}
+	return classes[0], nil
+}
+
+// Classes returns every embedded definition in name order.
+func Classes() ([]*models.Class, error) {
+	return academy.LoadClassesFS(files)
+}
+
+// Names lists the embedded classes.
+func Names() []string {
+	entries, _ := files.ReadDir(".")
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = strings.TrimSuffix(e.Name(), ".yaml")
+	}
+	return names
+}